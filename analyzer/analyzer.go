@@ -0,0 +1,192 @@
+// Package analyzer adapts asterisk patterns to the go/analysis framework,
+// so they can be run by go vet or multichecker based linters.
+package analyzer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"sync"
+
+	"github.com/Oppodelldog/asterisk"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Rule is a pattern whose matches are reported by the Analyzer.
+type Rule struct {
+	Matcher *asterisk.Matcher
+	// Message is reported for each match of the Matcher.
+	// It is an asterisk.Message template that is rendered using the nodes selected during the match,
+	// or using Selections if the conditions of the Matcher select into them.
+	Message    string
+	Selections asterisk.NodeSelections
+}
+
+// New returns an *analysis.Analyzer that reports each match of the given rules as diagnostic.
+// The name of the Matcher is used as category of the diagnostic.
+// If a Matcher rewrites the file, a SuggestedFix replacing the changed lines is attached. The file is printed
+// once before the walk and again after each match that changed it, a rewrite of lines that are not formatted
+// like gofmt prints them gets no SuggestedFix.
+// Matchers are walked on copies of the files, so the files of the pass are never modified.
+func New(name, doc string, rules ...Rule) *analysis.Analyzer {
+	var (
+		mu       sync.Mutex
+		pms      = make(asterisk.PatternMatchers, len(rules))
+		messages = make(map[*asterisk.Matcher]message, len(rules))
+		parseErr error
+	)
+
+	for i, rule := range rules {
+		tmpl, err := asterisk.ParseMessage(rule.Message)
+		if err != nil && parseErr == nil {
+			parseErr = err
+		}
+
+		pms[i] = rule.Matcher
+		messages[rule.Matcher] = message{tmpl: tmpl, selections: rule.Selections}
+	}
+
+	return &analysis.Analyzer{
+		Name: name,
+		Doc:  doc,
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			if parseErr != nil {
+				return nil, parseErr
+			}
+
+			// the Selections of rules are shared by all walks, so packages must not be walked concurrently.
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, file := range pass.Files {
+				src, err := readFile(pass, file)
+				if err != nil {
+					return nil, err
+				}
+
+				var clone = asterisk.Clone(file).(*ast.File)
+
+				r, err := newReporter(pass, messages, clone, src)
+				if err != nil {
+					return nil, err
+				}
+
+				err = asterisk.Walk(
+					clone,
+					pms,
+					asterisk.WithFileSet(pass.Fset),
+					asterisk.Intercept(r.report),
+					asterisk.OnChange(r.change),
+				)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return nil, nil
+		},
+	}
+}
+
+type message struct {
+	tmpl       *asterisk.Message
+	selections asterisk.NodeSelections
+}
+
+// reporter reports the matches in a file.
+type reporter struct {
+	pass      *analysis.Pass
+	messages  map[*asterisk.Matcher]message
+	file      *ast.File
+	alignment *alignment
+	changed   bool
+}
+
+// newReporter returns a reporter for the matches in the given file, src is the source the file was parsed from.
+func newReporter(
+	pass *analysis.Pass,
+	messages map[*asterisk.Matcher]message,
+	file *ast.File,
+	src []byte,
+) (*reporter, error) {
+	printed, err := formatNode(pass.Fset, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reporter{
+		pass:      pass,
+		messages:  messages,
+		file:      file,
+		alignment: newAlignment(pass.Fset.File(file.Pos()), src, printed),
+	}, nil
+}
+
+// change is called by the walk after each match that changed the file.
+func (r *reporter) change(*asterisk.Matcher, ast.Node) {
+	r.changed = true
+}
+
+func (r *reporter) report(pm *asterisk.Matcher, node ast.Node, s asterisk.NodeSelections, process func() error) error {
+	var pos, end = node.Pos(), node.End()
+
+	if r.messages[pm].selections != nil {
+		s = r.messages[pm].selections
+	}
+
+	msg, err := r.messages[pm].tmpl.Render(r.pass.Fset, s)
+	if err != nil {
+		return err
+	}
+
+	r.changed = false
+
+	if err := process(); err != nil {
+		return err
+	}
+
+	var diagnostic = analysis.Diagnostic{
+		Pos:      pos,
+		End:      end,
+		Category: pm.Name(),
+		Message:  msg,
+	}
+
+	if r.changed {
+		after, err := formatNode(r.pass.Fset, r.file)
+		if err != nil {
+			return err
+		}
+
+		if edits := r.alignment.textEdits(after); len(edits) > 0 {
+			diagnostic.SuggestedFixes = []analysis.SuggestedFix{{
+				Message:   msg,
+				TextEdits: edits,
+			}}
+		}
+	}
+
+	r.pass.Report(diagnostic)
+
+	return nil
+}
+
+func readFile(pass *analysis.Pass, file *ast.File) ([]byte, error) {
+	var name = pass.Fset.File(file.Pos()).Name()
+	if pass.ReadFile == nil {
+		return os.ReadFile(name)
+	}
+
+	return pass.ReadFile(name)
+}
+
+func formatNode(fileSet *token.FileSet, node ast.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fileSet, node); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"go/token"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"golang.org/x/tools/go/analysis"
+)
+
+// lineHunk replaces the lines between aStart and aEnd of a text by the lines between bStart and bEnd of another
+// text, given as line indices.
+type lineHunk struct {
+	aStart, aEnd int
+	bStart, bEnd int
+}
+
+// alignment maps the lines of the printed file to the source it was parsed from. It is computed once per file
+// and updated by the lines each match changed.
+type alignment struct {
+	tokenFile *token.File
+	text      string
+	lines     []string
+	// offsets holds the offset in the source of each line, or -1 if the line is printed differently
+	// than it is written in the source, e.g. since it is not formatted or was rewritten by an earlier match.
+	offsets []int
+}
+
+// newAlignment aligns the lines of the printed file with src.
+func newAlignment(tokenFile *token.File, src, printed []byte) *alignment {
+	var a = &alignment{tokenFile: tokenFile, text: string(src), lines: splitLines(string(src))}
+
+	a.offsets = make([]int, len(a.lines))
+	for i, offset := 0, 0; i < len(a.lines); i++ {
+		a.offsets[i] = offset
+		offset += len(a.lines[i])
+	}
+
+	a.textEdits(printed)
+
+	return a
+}
+
+// textEdits returns the edits of the lines that differ between the printed file and text, placed in the source
+// by the alignment, and aligns text with the source. If a changed line is not aligned, no edits are returned.
+func (a *alignment) textEdits(text []byte) []analysis.TextEdit {
+	var (
+		lines   = splitLines(string(text))
+		offsets = make([]int, len(lines))
+		hunks   = diffLines(a.text, string(text))
+		edits   []analysis.TextEdit
+		aligned = true
+		ai, bi  int
+	)
+
+	hunks = append(hunks, lineHunk{aStart: len(a.lines), aEnd: len(a.lines), bStart: len(lines), bEnd: len(lines)})
+
+	for _, h := range hunks {
+		for ; bi < h.bStart; ai, bi = ai+1, bi+1 {
+			offsets[bi] = a.offsets[ai]
+		}
+
+		for ; bi < h.bEnd; bi++ {
+			offsets[bi] = -1
+		}
+
+		ai = h.aEnd
+
+		if h.aStart == h.aEnd && h.bStart == h.bEnd {
+			continue
+		}
+
+		start, end, ok := a.source(h.aStart, h.aEnd)
+		if !ok {
+			aligned = false
+
+			continue
+		}
+
+		edits = append(edits, analysis.TextEdit{
+			Pos:     a.tokenFile.Pos(start),
+			End:     a.tokenFile.Pos(end),
+			NewText: []byte(strings.Join(lines[h.bStart:h.bEnd], "")),
+		})
+	}
+
+	a.text, a.lines, a.offsets = string(text), lines, offsets
+
+	if !aligned {
+		return nil
+	}
+
+	return edits
+}
+
+// source returns the range of the source that holds the printed lines between start and end. It fails if one
+// of the lines is not aligned.
+func (a *alignment) source(start, end int) (int, int, bool) {
+	if start == end {
+		switch {
+		case start < len(a.lines) && a.offsets[start] >= 0:
+			return a.offsets[start], a.offsets[start], true
+		case start > 0 && a.offsets[start-1] >= 0:
+			var offset = a.offsets[start-1] + len(a.lines[start-1])
+
+			return offset, offset, true
+		case len(a.lines) == 0:
+			return 0, 0, true
+		}
+
+		return 0, 0, false
+	}
+
+	for i := start; i < end; i++ {
+		if a.offsets[i] < 0 || i > start && a.offsets[i] != a.offsets[i-1]+len(a.lines[i-1]) {
+			return 0, 0, false
+		}
+	}
+
+	return a.offsets[start], a.offsets[end-1] + len(a.lines[end-1]), true
+}
+
+// splitLines splits s after each line break, as diffLines does.
+func splitLines(s string) []string {
+	var lines = strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines returns the hunks of lines turning a into b.
+func diffLines(a, b string) []lineHunk {
+	var (
+		dmp          = diffmatchpatch.New()
+		ac, bc, _    = dmp.DiffLinesToChars(a, b)
+		hunks        []lineHunk
+		aLine, bLine int
+		open         bool
+	)
+
+	// each line is encoded as one rune.
+	for _, d := range dmp.DiffMain(ac, bc, false) {
+		var n = utf8.RuneCountInString(d.Text)
+
+		if d.Type == diffmatchpatch.DiffEqual {
+			aLine += n
+			bLine += n
+			open = false
+
+			continue
+		}
+
+		if !open {
+			hunks = append(hunks, lineHunk{aStart: aLine, aEnd: aLine, bStart: bLine, bEnd: bLine})
+			open = true
+		}
+
+		var h = &hunks[len(hunks)-1]
+
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			aLine += n
+			h.aEnd = aLine
+		case diffmatchpatch.DiffInsert:
+			bLine += n
+			h.bEnd = bLine
+		}
+	}
+
+	return hunks
+}
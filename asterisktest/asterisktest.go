@@ -0,0 +1,255 @@
+// Package asterisktest runs asterisk rules against fixture files, like analysistest does for analyzers.
+//
+// A fixture is a Go file annotated with comments expecting diagnostics on their line:
+//
+//	logrus.Error("msg") // want "use zerolog" "another diagnostic"
+//
+// Each expectation is a quoted regular expression that has to match the message of a diagnostic.
+// If a fixture has a .golden file, the rewritten fixture has to match it. Running the tests with
+// ASTERISK_UPDATE=1, or with -update if the test package defines a bool flag "update",
+// writes the rewritten fixtures to their .golden files.
+package asterisktest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Oppodelldog/asterisk"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	wantPrefix   = "// want "
+	goldenSuffix = ".golden"
+	updateFlag   = "update"
+	updateEnv    = "ASTERISK_UPDATE"
+)
+
+// Testing is the part of *testing.T used by Run.
+type Testing interface {
+	Errorf(format string, args ...interface{})
+	Helper()
+}
+
+// Result holds the outcome of running the rules on a fixture.
+type Result struct {
+	File        string
+	Diagnostics []asterisk.Diagnostic
+	// Fixed is the fixture with the edits of all diagnostics applied.
+	Fixed []byte
+}
+
+type expectation struct {
+	pos     token.Position
+	pattern *regexp.Regexp
+	matched bool
+}
+
+// Run runs the rules on all .go fixture files of dir.
+// It reports unexpected and missing diagnostics, and rewrites that do not match the .golden files.
+// Inline suppressions in the fixtures are applied, as they are by the asterisk command.
+func Run(t Testing, dir string, rules asterisk.Rules) []Result {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Errorf("%v", err)
+
+		return nil
+	}
+
+	sort.Strings(files)
+
+	var results []Result
+
+	for _, file := range files {
+		result, err := runFile(t, file, rules)
+		if err != nil {
+			t.Errorf("%v", err)
+
+			continue
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func runFile(t Testing, file string, rules asterisk.Rules) (Result, error) {
+	t.Helper()
+
+	var result = Result{File: file}
+
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return result, err
+	}
+
+	var fileSet = token.NewFileSet()
+
+	f, err := parser.ParseFile(fileSet, file, src, parser.ParseComments)
+	if err != nil {
+		return result, err
+	}
+
+	expectations, err := parseExpectations(fileSet, f)
+	if err != nil {
+		return result, err
+	}
+
+	var (
+		suppressions = asterisk.ParseSuppressions(fileSet, f)
+		edits        []asterisk.TextEdit
+	)
+
+	err = asterisk.Walk(f, rules.Matchers(fileSet, f, suppressions.Filter(func(d asterisk.Diagnostic) {
+		result.Diagnostics = append(result.Diagnostics, d)
+		edits = append(edits, d.Edits...)
+	})), asterisk.WithFileSet(fileSet))
+	if err != nil {
+		return result, err
+	}
+
+	checkDiagnostics(t, result.Diagnostics, expectations)
+
+	fixed, _, err := asterisk.ApplyEdits(src, edits)
+	if err != nil {
+		return result, err
+	}
+
+	if formatted, err := format.Source(fixed); err == nil {
+		fixed = formatted
+	}
+
+	result.Fixed = fixed
+
+	return result, checkGolden(t, file, src, fixed)
+}
+
+// parseExpectations reads the quoted regular expressions of all want comments.
+func parseExpectations(fileSet *token.FileSet, f *ast.File) ([]*expectation, error) {
+	var expectations []*expectation
+
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			if !strings.HasPrefix(c.Text, wantPrefix) {
+				continue
+			}
+
+			var (
+				pos  = fileSet.Position(c.Pos())
+				text = strings.TrimSpace(strings.TrimPrefix(c.Text, wantPrefix))
+			)
+
+			for text != "" {
+				quoted, err := strconv.QuotedPrefix(text)
+				if err != nil {
+					return nil, fmt.Errorf("%v: invalid want comment: %w", pos, err)
+				}
+
+				expr, _ := strconv.Unquote(quoted)
+
+				pattern, err := regexp.Compile(expr)
+				if err != nil {
+					return nil, fmt.Errorf("%v: invalid want comment: %w", pos, err)
+				}
+
+				expectations = append(expectations, &expectation{pos: pos, pattern: pattern})
+				text = strings.TrimSpace(text[len(quoted):])
+			}
+		}
+	}
+
+	return expectations, nil
+}
+
+func checkDiagnostics(t Testing, diagnostics []asterisk.Diagnostic, expectations []*expectation) {
+	t.Helper()
+
+	for _, d := range diagnostics {
+		if !expect(expectations, d) {
+			t.Errorf("%v: unexpected diagnostic: %v", d.Pos, d.Message)
+		}
+	}
+
+	for _, e := range expectations {
+		if !e.matched {
+			t.Errorf("%v: no diagnostic was reported matching %q", e.pos, e.pattern)
+		}
+	}
+}
+
+// expect marks the first unmatched expectation on the line of the diagnostic that matches its message.
+func expect(expectations []*expectation, d asterisk.Diagnostic) bool {
+	for _, e := range expectations {
+		if !e.matched && e.pos.Line == d.Pos.Line && e.pattern.MatchString(d.Message) {
+			e.matched = true
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// updating reports whether the .golden files are written. The flag is looked up when the tests run,
+// so asterisktest does not define it and cannot conflict with packages that do.
+func updating() bool {
+	if f := flag.Lookup(updateFlag); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if update, ok := getter.Get().(bool); ok && update {
+				return true
+			}
+		}
+	}
+
+	update, _ := strconv.ParseBool(os.Getenv(updateEnv))
+
+	return update
+}
+
+// checkGolden compares the rewritten fixture with its .golden file, or writes it when updating.
+// Fixtures without .golden file are not checked, updating creates them only for fixtures that were rewritten.
+func checkGolden(t Testing, file string, src, fixed []byte) error {
+	t.Helper()
+
+	var golden = file + goldenSuffix
+
+	want, err := os.ReadFile(golden)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if updating() {
+		if os.IsNotExist(err) && bytes.Equal(src, fixed) {
+			return nil
+		}
+
+		return os.WriteFile(golden, fixed, 0600)
+	}
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if !bytes.Equal(want, fixed) {
+		var dmp = diffmatchpatch.New()
+
+		t.Errorf("%v: rewritten fixture does not match %v:\n%v", file, filepath.Base(golden),
+			dmp.DiffPrettyText(dmp.DiffMain(string(want), string(fixed), false)))
+	}
+
+	return nil
+}
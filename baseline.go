@@ -0,0 +1,120 @@
+package asterisk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const baselineVersion = 1
+
+// ErrBaselineVersion is returned by LoadBaseline for baselines written by an incompatible version.
+var ErrBaselineVersion = errors.New("unsupported baseline version")
+
+// Baseline records existing findings, so only new findings are reported.
+// Findings are identified by rule, file and fingerprint, so edits that move a finding do not invalidate it.
+type Baseline struct {
+	Version  int               `json:"version"`
+	Findings []BaselineFinding `json:"findings"`
+}
+
+// BaselineFinding is a finding recorded in a Baseline, Count is the number of equal findings.
+type BaselineFinding struct {
+	Rule        string `json:"rule"`
+	File        string `json:"file"`
+	Fingerprint string `json:"fingerprint"`
+	Count       int    `json:"count"`
+}
+
+type baselineKey struct {
+	rule, file, fingerprint string
+}
+
+func keyOf(d Diagnostic) baselineKey {
+	return baselineKey{rule: d.Rule, file: filepath.ToSlash(d.Pos.Filename), fingerprint: d.Fingerprint}
+}
+
+// NewBaseline records the given diagnostics in a Baseline.
+func NewBaseline(diagnostics []Diagnostic) *Baseline {
+	var counts = map[baselineKey]int{}
+	for _, d := range diagnostics {
+		counts[keyOf(d)]++
+	}
+
+	var b = &Baseline{Version: baselineVersion, Findings: []BaselineFinding{}}
+	for key, count := range counts {
+		b.Findings = append(b.Findings, BaselineFinding{
+			Rule:        key.rule,
+			File:        key.file,
+			Fingerprint: key.fingerprint,
+			Count:       count,
+		})
+	}
+
+	sort.Slice(b.Findings, func(i, j int) bool {
+		var a, c = b.Findings[i], b.Findings[j]
+		if a.File != c.File {
+			return a.File < c.File
+		}
+
+		if a.Rule != c.Rule {
+			return a.Rule < c.Rule
+		}
+
+		return a.Fingerprint < c.Fingerprint
+	})
+
+	return b
+}
+
+// LoadBaseline reads a Baseline from the given file. Baselines of other versions are rejected with ErrBaselineVersion.
+func LoadBaseline(file string) (*Baseline, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("%w: %v", ErrBaselineVersion, b.Version)
+	}
+
+	return &b, nil
+}
+
+// Save writes the Baseline to the given file.
+func (b *Baseline) Save(file string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, append(data, '\n'), 0600)
+}
+
+// Filter returns a ReportFunc that passes only diagnostics that are not part of the Baseline to report.
+// Each recorded finding absorbs as many diagnostics as it was counted.
+func (b *Baseline) Filter(report ReportFunc) ReportFunc {
+	var remaining = map[baselineKey]int{}
+	for _, f := range b.Findings {
+		remaining[baselineKey{rule: f.Rule, file: f.File, fingerprint: f.Fingerprint}] += f.Count
+	}
+
+	return func(d Diagnostic) {
+		var key = keyOf(d)
+		if remaining[key] > 0 {
+			remaining[key]--
+
+			return
+		}
+
+		report(d)
+	}
+}
@@ -0,0 +1,98 @@
+package asterisk
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+var posType = reflect.TypeOf(token.NoPos)
+
+// CloneOption configures Clone.
+type CloneOption func(*cloner)
+
+// ResetPositions sets all positions of the cloned nodes to token.NoPos.
+func ResetPositions() CloneOption {
+	return func(c *cloner) {
+		c.resetPositions = true
+	}
+}
+
+// Clone returns a deep copy of the given subtree, so it can be inserted into a tree without
+// sharing nodes with the place it was taken from.
+// References to ast.Object and ast.Scope are not copied but dropped, since they point outside of the subtree.
+func Clone(n ast.Node, opts ...CloneOption) ast.Node {
+	if n == nil {
+		return nil
+	}
+
+	var c = cloner{copies: map[ptrKey]reflect.Value{}}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c.clone(reflect.ValueOf(n)).Interface().(ast.Node)
+}
+
+type cloner struct {
+	resetPositions bool
+	copies         map[ptrKey]reflect.Value
+}
+
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		var i = reflect.New(v.Type()).Elem()
+		i.Set(c.clone(v.Elem()))
+
+		return i
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		if v.Type() == objectType || v.Type() == scopeType {
+			return reflect.Zero(v.Type())
+		}
+
+		var key = ptrKey{t: v.Type(), ptr: v.Pointer()}
+		if p, ok := c.copies[key]; ok {
+			return p
+		}
+
+		var p = reflect.New(v.Type().Elem())
+		c.copies[key] = p
+		p.Elem().Set(c.clone(v.Elem()))
+
+		return p
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		var s = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(c.clone(v.Index(i)))
+		}
+
+		return s
+	case reflect.Struct:
+		var s = reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			s.Field(i).Set(c.clone(v.Field(i)))
+		}
+
+		return s
+	default:
+		if c.resetPositions && v.Type() == posType {
+			return reflect.Zero(posType)
+		}
+
+		return v
+	}
+}
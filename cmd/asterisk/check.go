@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Oppodelldog/asterisk"
+	"github.com/Oppodelldog/asterisk/report"
+)
+
+func check(args []string) (int, error) {
+	var (
+		flags     = flag.NewFlagSet("check", flag.ContinueOnError)
+		ruleFlags = addRuleFlags(flags)
+		fix       = flags.Bool("fix", false, "apply the replacements of the rules")
+		format    = flags.String("format", "text", "report format, one of "+strings.Join(report.Formats(), ", "))
+		baseline  = flags.String("baseline", "", "report only findings not recorded in the given baseline file")
+		write     = flags.String("write-baseline", "", "record all findings in the given baseline file")
+		diff      = flags.String("diff", "", "report and fix only findings on lines changed by the given diff, - reads stdin")
+		diffRoot  = flags.String("diff-root", "", "directory the files of -diff are relative to, default the root of the git repository")
+		workers   = flags.Int("workers", 0, "number of files checked concurrently, default GOMAXPROCS")
+		timeout   = flags.Duration("timeout", 0, "time budget per file, 0 means unlimited")
+		matches   = flags.Int("max-matches", 0, "maximum number of matches per file, 0 means unlimited")
+		fileSize  = flags.Int64("max-file-size", 0, "skip files larger than the given number of bytes, 0 means unlimited")
+		indexFile = flags.String("index", "", "skip files that cannot match according to the given index")
+		profile   = flags.Bool("profile", false, "write the calls, matches and time of the conditions of each rule to stderr")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return 2, err
+	}
+
+	rules, err := ruleFlags.load()
+	if err != nil {
+		return 2, err
+	}
+
+	reporter, err := report.New(*format, os.Stdout, report.WithRules(rules))
+	if err != nil {
+		return 2, err
+	}
+
+	files, err := goFiles(flags.Args())
+	if err != nil {
+		return 2, err
+	}
+
+	if *indexFile != "" {
+		if files, err = candidates(*indexFile, files, rules); err != nil {
+			return 2, err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var walkOptions = []asterisk.WalkOption{asterisk.Timeout(*timeout), asterisk.MaxMatches(*matches)}
+
+	if *profile {
+		var p = asterisk.NewProfiler()
+
+		walkOptions = append(walkOptions, asterisk.Profile(p))
+
+		defer writeProfile(p)
+	}
+
+	var engine = asterisk.NewEngine(asterisk.NewRuleTree(rules).Matchers,
+		asterisk.Workers(*workers),
+		asterisk.WithSuppressions(),
+		asterisk.MaxFileSize(*fileSize),
+		asterisk.WithWalkOptions(walkOptions...),
+	)
+
+	if *write != "" {
+		return writeBaseline(*write, engine.RunFilesContext(ctx, files))
+	}
+
+	var filter filterFunc = func(report asterisk.ReportFunc) asterisk.ReportFunc { return report }
+
+	if *baseline != "" {
+		b, err := asterisk.LoadBaseline(*baseline)
+		if err != nil {
+			return 2, err
+		}
+
+		filter = b.Filter
+	}
+
+	if *diff != "" {
+		d, err := asterisk.LoadDiff(*diff)
+		if err != nil {
+			return 2, err
+		}
+
+		if err := d.SetRoot(repositoryRoot(*diffRoot)); err != nil {
+			return 2, err
+		}
+
+		filter = chain(d.Filter, filter)
+	}
+
+	var unfixed int
+
+	for _, r := range engine.RunFilesContext(ctx, files) {
+		if err := limitErr(r); err != nil {
+			return 2, err
+		}
+
+		var diagnostics = filter.apply(r.Diagnostics)
+
+		if *fix {
+			if diagnostics, err = fixFile(r.Name, r.Src, diagnostics); err != nil {
+				return 2, err
+			}
+		}
+
+		for _, d := range diagnostics {
+			if err := reporter.Report(d); err != nil {
+				return 2, err
+			}
+		}
+
+		unfixed += len(diagnostics)
+	}
+
+	if err := reporter.Close(); err != nil {
+		return 2, err
+	}
+
+	if unfixed > 0 {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// repositoryRoot returns the given directory, or the root of the git repository of the working directory
+// if none is given. Outside of a git repository, the working directory is returned.
+func repositoryRoot(dir string) string {
+	if dir != "" {
+		return dir
+	}
+
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "."
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// writeBaseline records the findings of the given results in a baseline file.
+func writeBaseline(baselineFile string, results []asterisk.FileResult) (int, error) {
+	var diagnostics []asterisk.Diagnostic
+
+	for _, r := range results {
+		if err := limitErr(r); err != nil {
+			return 2, err
+		}
+
+		diagnostics = append(diagnostics, r.Diagnostics...)
+	}
+
+	if err := asterisk.NewBaseline(diagnostics).Save(baselineFile); err != nil {
+		return 2, err
+	}
+
+	return 0, nil
+}
+
+// writeProfile writes the report of the profiler to stderr.
+func writeProfile(p *asterisk.Profiler) {
+	if err := p.WriteReport(os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// limitErr warns about files that exceeded a limit. A file that only exceeded a limit is no error,
+// the match errors of a file are returned even if it exceeded a limit.
+func limitErr(r asterisk.FileResult) error {
+	var limit *asterisk.LimitError
+	if !errors.As(r.Err, &limit) {
+		return r.Err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v: %v\n", r.Name, limit)
+
+	if r.Err == error(limit) {
+		return nil
+	}
+
+	var matchErrs asterisk.MatchErrors
+	if errors.As(r.Err, &matchErrs) {
+		return matchErrs
+	}
+
+	return r.Err
+}
+
+// filterFunc wraps a ReportFunc to pass only some diagnostics to it.
+type filterFunc func(asterisk.ReportFunc) asterisk.ReportFunc
+
+// chain returns a filter applying outer to the diagnostics passed by inner.
+func chain(outer, inner filterFunc) filterFunc {
+	return func(report asterisk.ReportFunc) asterisk.ReportFunc {
+		return inner(outer(report))
+	}
+}
+
+// apply returns the diagnostics passed by the filter.
+func (filter filterFunc) apply(diagnostics []asterisk.Diagnostic) []asterisk.Diagnostic {
+	var (
+		passed []asterisk.Diagnostic
+		report = filter(func(d asterisk.Diagnostic) {
+			passed = append(passed, d)
+		})
+	)
+
+	for _, d := range diagnostics {
+		report(d)
+	}
+
+	return passed
+}
+
+// fixFile applies the edits of the diagnostics and returns the diagnostics that could not be fixed.
+func fixFile(file string, src []byte, diagnostics []asterisk.Diagnostic) ([]asterisk.Diagnostic, error) {
+	var (
+		edits   []asterisk.TextEdit
+		unfixed []asterisk.Diagnostic
+	)
+
+	for _, d := range diagnostics {
+		edits = append(edits, d.Edits...)
+	}
+
+	fixed, skipped, err := asterisk.ApplyEdits(src, edits)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range diagnostics {
+		if len(d.Edits) == 0 || containsEdit(skipped, d.Edits[0]) {
+			unfixed = append(unfixed, d)
+		}
+	}
+
+	if len(edits) == len(skipped) {
+		return unfixed, nil
+	}
+
+	if formatted, err := format.Source(fixed); err == nil {
+		fixed = formatted
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return unfixed, os.WriteFile(file, fixed, info.Mode())
+}
+
+func containsEdit(edits []asterisk.TextEdit, edit asterisk.TextEdit) bool {
+	for _, e := range edits {
+		if e == edit {
+			return true
+		}
+	}
+
+	return false
+}
+
+// goFiles returns the go files of the given paths, directories are searched recursively.
+func goFiles(paths []string) ([]string, error) {
+	var files []string
+
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	for _, path := range paths {
+		path = strings.TrimSuffix(path, "...")
+		if path == "" {
+			path = "."
+		}
+
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				if file != path && skipDir(info.Name()) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if strings.HasSuffix(file, ".go") {
+				files = append(files, file)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func skipDir(name string) bool {
+	return name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/Oppodelldog/asterisk"
+	"github.com/Oppodelldog/asterisk/index"
+)
+
+const defaultIndex = ".asterisk-index.json"
+
+// indexFiles creates or updates the index of the go files of the given paths.
+func indexFiles(args []string) (int, error) {
+	var (
+		flags = flag.NewFlagSet("index", flag.ContinueOnError)
+		file  = flags.String("index", defaultIndex, "index file to create or update")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return 2, err
+	}
+
+	files, err := goFiles(flags.Args())
+	if err != nil {
+		return 2, err
+	}
+
+	idx, err := index.Load(*file)
+	if errors.Is(err, fs.ErrNotExist) {
+		idx, err = index.New(), nil
+	}
+
+	if err != nil {
+		return 2, err
+	}
+
+	stats, err := idx.Update(files)
+	if err != nil {
+		return 2, err
+	}
+
+	if err := idx.Save(*file); err != nil {
+		return 2, err
+	}
+
+	fmt.Fprintln(os.Stderr, stats)
+
+	return 0, nil
+}
+
+// candidates returns the files that may match the rules according to the given index.
+func candidates(indexFile string, files []string, rules asterisk.Rules) ([]string, error) {
+	idx, err := index.Load(indexFile)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := index.RulesQuery(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx.Candidates(files, q)
+}
@@ -0,0 +1,54 @@
+// Command asterisk searches and rewrites go code using declarative rule files.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) (int, error)
+}
+
+var commands = map[string]command{
+	"check": {usage: "check -rules <file> | -builtin <packs> [-enable ids] [-disable ids] [-fix] [-index <file>] [-profile] [path ...]", run: check},
+	"index": {usage: "index [-index <file>] [path ...]", run: indexFiles},
+	"rules": {usage: "rules -rules <file> | -builtin <packs> [-enable ids] [-disable ids] [-markdown | -tree]", run: rules},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	code, err := cmd.run(os.Args[2:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	os.Exit(code)
+}
+
+func usage() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage:")
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "\tasterisk %v\n", commands[name].usage)
+	}
+}
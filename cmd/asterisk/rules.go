@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/Oppodelldog/asterisk"
+	"github.com/Oppodelldog/asterisk/formatting"
+)
+
+var (
+	errMissingRules   = errors.New("missing -rules or -builtin flag")
+	errUnknownBuiltin = errors.New("unknown built-in rule pack")
+)
+
+var builtins = map[string]func() asterisk.Rules{
+	"formatting": formatting.Rules,
+}
+
+var markdown = template.Must(template.New("markdown").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`# Rules
+{{range .}}
+## {{.ID}}{{if .Title}}: {{.Title}}{{end}}
+
+{{if .Description}}{{.Description}}
+
+{{end}}* Severity: {{.Severity}}
+{{- if .Category}}
+* Category: {{.Category}}{{end}}
+{{- if .Tags}}
+* Tags: {{join .Tags ", "}}{{end}}
+{{range .Examples.Bad}}
+Bad:
+
+` + "```go" + `
+{{.}}
+` + "```" + `
+{{end}}{{range .Examples.Good}}
+Good:
+
+` + "```go" + `
+{{.}}
+` + "```" + `
+{{end}}{{end}}`))
+
+// ruleFlags are the flags selecting the rules of a command.
+type ruleFlags struct {
+	rulesFile, builtin, enable, disable *string
+}
+
+func addRuleFlags(flags *flag.FlagSet) *ruleFlags {
+	return &ruleFlags{
+		rulesFile: flags.String("rules", "", "YAML or JSON rule file"),
+		builtin:   flags.String("builtin", "", "comma separated built-in rule packs: "+strings.Join(builtinNames(), ", ")),
+		enable:    flags.String("enable", "", "comma separated ids, categories or tags of the rules to run, default all"),
+		disable:   flags.String("disable", "", "comma separated ids, categories or tags of the rules not to run"),
+	}
+}
+
+// load registers the rules of the rule file and the built-in rule packs and selects the enabled ones.
+func (f *ruleFlags) load() (asterisk.Rules, error) {
+	var registry = asterisk.NewRegistry()
+
+	if *f.rulesFile == "" && *f.builtin == "" {
+		return nil, errMissingRules
+	}
+
+	if *f.rulesFile != "" {
+		rules, err := asterisk.LoadRules(*f.rulesFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := registry.Register(rules...); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range split(*f.builtin) {
+		pack, ok := builtins[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", errUnknownBuiltin, name)
+		}
+
+		if err := registry.Register(pack()...); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry.Select(split(*f.enable), split(*f.disable))
+}
+
+func rules(args []string) (int, error) {
+	var (
+		flags     = flag.NewFlagSet("rules", flag.ContinueOnError)
+		ruleFlags = addRuleFlags(flags)
+		md        = flags.Bool("markdown", false, "render the documentation of the rules as Markdown")
+		tree      = flags.Bool("tree", false, "print the decision tree the patterns of the rules are compiled to")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return 2, err
+	}
+
+	rules, err := ruleFlags.load()
+	if err != nil {
+		return 2, err
+	}
+
+	switch {
+	case *md:
+		err = markdown.Execute(os.Stdout, rules)
+	case *tree:
+		_, err = fmt.Fprint(os.Stdout, asterisk.NewRuleTree(rules).Tree())
+	default:
+		err = listRules(os.Stdout, rules)
+	}
+
+	if err != nil {
+		return 2, err
+	}
+
+	return 0, nil
+}
+
+// listRules writes a table of the given rules.
+func listRules(w io.Writer, rules asterisk.Rules) error {
+	var tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tSEVERITY\tCATEGORY\tTAGS\tTITLE")
+
+	for _, r := range rules {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\n", r.ID, r.Severity, r.Category, strings.Join(r.Tags, ","), r.Title)
+	}
+
+	return tw.Flush()
+}
+
+func builtinNames() []string {
+	var names []string
+	for name := range builtins {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// split splits a comma separated flag value, empty elements are dropped.
+func split(value string) []string {
+	var values []string
+
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
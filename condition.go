@@ -3,6 +3,7 @@ package asterisk
 import (
 	"go/ast"
 	"reflect"
+	"sync"
 )
 
 type (
@@ -11,12 +12,98 @@ type (
 	ExprCondition       func(ast.Expr) bool
 	FilesMapCondition   func(Files map[string]*ast.File) bool
 	ImportsMapCondition func(map[string]*ast.Object) bool
-	NodeCondition       func(ast.Node) bool
-	NodesCondition      func([]ast.Node) bool
+	IntCondition        func(int) bool
 	ScopeCondition      func(*ast.Scope) bool
 	StringCondition     func(string) bool
 )
 
+// NodeCondition matches a single node. Conditions are created by the constructors of this package,
+// custom conditions by Func. A condition carries the node kinds it accepts and the label profiles record it by,
+// it holds no state, so it can be shared by concurrent walks.
+type NodeCondition struct {
+	eval func(ev *evaluation, n ast.Node) bool
+	// kinds holds the kinds the condition accepts, it is nil if the condition may accept any node.
+	kinds map[reflect.Type]bool
+	// label names the condition in profiles, conditions without label are not recorded.
+	label string
+}
+
+// NodesCondition matches the nodes of a slice field, e.g. the arguments of a call.
+type NodesCondition struct {
+	eval  func(ev *evaluation, nodes []ast.Node) bool
+	label string
+}
+
+// evaluation is the state of a walk the conditions are evaluated in.
+type evaluation struct {
+	// profile records the labeled conditions, it is nil if the walk is not profiled.
+	profile *walkProfile
+	// trees holds the last match of each DecisionTree, which is shared by the conditions of its patterns.
+	// Evaluations without trees match a DecisionTree for each condition.
+	trees map[*DecisionTree]*treeEvaluation
+	// state is the state of the match the conditions are evaluated for, nodes are selected into it.
+	// It is nil outside of matches, the nodes selected there are dropped.
+	state *matchState
+}
+
+// detached evaluates conditions outside of walks, it holds no state and is never written.
+var detached = &evaluation{}
+
+func newEvaluation() *evaluation {
+	return &evaluation{trees: map[*DecisionTree]*treeEvaluation{}}
+}
+
+// Match reports whether the condition matches the given node outside of a walk.
+func (c NodeCondition) Match(n ast.Node) bool {
+	return c.match(detached, n)
+}
+
+func (c NodeCondition) match(ev *evaluation, n ast.Node) bool {
+	if ev.profile == nil || c.label == "" {
+		return c.eval(ev, n)
+	}
+
+	return ev.profile.call(c.label, func() bool {
+		return c.eval(ev, n)
+	})
+}
+
+// Match reports whether the condition matches the given nodes outside of a walk.
+func (c NodesCondition) Match(nodes []ast.Node) bool {
+	return c.match(detached, nodes)
+}
+
+func (c NodesCondition) match(ev *evaluation, nodes []ast.Node) bool {
+	if ev.profile == nil || c.label == "" {
+		return c.eval(ev, nodes)
+	}
+
+	return ev.profile.call(c.label, func() bool {
+		return c.eval(ev, nodes)
+	})
+}
+
+// typed returns the condition of a constructor, it accepts the kind of the given node and is labeled by its name.
+func typed(kind ast.Node, eval func(ev *evaluation, n ast.Node) bool) NodeCondition {
+	return NodeCondition{eval: eval, kinds: kindSet([]ast.Node{kind}), label: kindName(kind)}
+}
+
+// valued returns the condition of a constructor matching nodes by a value, it is labeled by the name of the kind
+// and the value, e.g. "Ident(logrus)".
+func valued(kind ast.Node, value string, eval func(ev *evaluation, n ast.Node) bool) NodeCondition {
+	var c = typed(kind, eval)
+
+	c.label += "(" + value + ")"
+
+	return c
+}
+
+// narrowed returns a condition evaluating eval, which must only match nodes c matches,
+// so it accepts the kinds of c. It is not labeled, the conditions eval calls are recorded on their own.
+func narrowed(c NodeCondition, eval func(ev *evaluation, n ast.Node) bool) NodeCondition {
+	return NodeCondition{eval: eval, kinds: c.kinds}
+}
+
 /**************************************************************************
 	concrete expression nodes
 **************************************************************************/
@@ -28,79 +115,79 @@ func BadExpr() NodeCondition {
 
 // Ident check if the given ast.Ident name matches the requested one.
 func Ident(name string) NodeCondition {
-	return func(n ast.Node) bool {
+	return valued(new(ast.Ident), name, func(_ *evaluation, n ast.Node) bool {
 		if ident, ok := n.(*ast.Ident); ok {
 			return ident.Name == name
 		}
 
 		return false
-	}
+	})
 }
 
 // IdentExpr check if the given ast.IdentExpr name matches the requested one.
 func IdentExpr(name string) NodeCondition {
-	return func(n ast.Node) bool {
+	return valued(new(ast.Ident), name, func(_ *evaluation, n ast.Node) bool {
 		if ident, ok := n.(*ast.Ident); ok {
 			return ident.Name == name
 		}
 
 		return false
-	}
+	})
 }
 
 // Ellipsis check if the given ast.Ellipsis matches the given conditions.
 func Ellipsis(elem NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.Ellipsis), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.Ellipsis); ok {
-			return elem(e.Elt)
+			return elem.match(ev, e.Elt)
 		}
 
 		return false
-	}
+	})
 }
 
 // BasicLit check if the given ast.BasicLit matches the given conditions.
 func BasicLit(value string) NodeCondition {
-	return func(n ast.Node) bool {
+	return valued(new(ast.BasicLit), value, func(_ *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.BasicLit); ok {
 			return e.Value == value
 		}
 
 		return false
-	}
+	})
 }
 
 // FuncLit check if the given ast.FuncLit matches the given conditions.
 func FuncLit(t, block NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.FuncLit), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.FuncLit); ok {
-			return t(e.Type) && block(e.Body)
+			return t.match(ev, e.Type) && block.match(ev, e.Body)
 		}
 
 		return false
-	}
+	})
 }
 
 // CompositeLit check if the given ast.ParenExpr matches the given conditions.
 func CompositeLit(t NodeCondition, args NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.CompositeLit), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.CompositeLit); ok {
-			return t(e.Type) && args(toNodes(e.Elts))
+			return t.match(ev, e.Type) && matchNodes(ev, args, e.Elts)
 		}
 
 		return false
-	}
+	})
 }
 
 // ParenExpr check if the given ast.ParenExpr matches the given conditions.
 func ParenExpr(x NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.ParenExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.ParenExpr); ok {
-			return x(e.X)
+			return x.match(ev, e.X)
 		}
 
 		return false
-	}
+	})
 }
 
 // Expr check if the given ast.Expr matches the given condition.
@@ -112,120 +199,120 @@ func Expr(x ExprCondition) ExprCondition {
 
 // Exprs check if the given []ast.Node matches the given conditions in sequence.
 func Exprs(x []NodeCondition) NodesCondition {
-	return func(n []ast.Node) bool {
+	return NodesCondition{label: "Exprs", eval: func(ev *evaluation, n []ast.Node) bool {
 		if len(n) != len(x) {
 			return false
 		}
 
 		for i := range n {
 			if len(x) > i {
-				if !x[i](n[i]) {
+				if !x[i].match(ev, n[i]) {
 					return false
 				}
 			}
 		}
 
 		return true
-	}
+	}}
 }
 
 // SelectorExpr check if the given ast.SelectorExpr matches the given conditions.
 func SelectorExpr(x, sel NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.SelectorExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.SelectorExpr); ok {
-			return x(e.X) && sel(e.Sel)
+			return x.match(ev, e.X) && sel.match(ev, e.Sel)
 		}
 
 		return false
-	}
+	})
 }
 
 // IndexExpr check if the given ast.IndexExpr matches the given conditions.
 func IndexExpr(x, index NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.IndexExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.IndexExpr); ok {
-			return x(e.X) && index(e.Index)
+			return x.match(ev, e.X) && index.match(ev, e.Index)
 		}
 
 		return false
-	}
+	})
 }
 
 // SliceExpr check if the given ast.SliceExpr matches the given conditions.
 func SliceExpr(x, low, high, max NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.SliceExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.SliceExpr); ok {
-			return x(e.X) && low(e.Low) && high(e.High) && max(e.Max)
+			return x.match(ev, e.X) && low.match(ev, e.Low) && high.match(ev, e.High) && max.match(ev, e.Max)
 		}
 
 		return false
-	}
+	})
 }
 
 // TypeAssertExpr check if the given ast.TypeAssertExpr matches the given conditions.
 func TypeAssertExpr(x, t NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.TypeAssertExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.TypeAssertExpr); ok {
-			return x(e.X) && t(e.Type)
+			return x.match(ev, e.X) && t.match(ev, e.Type)
 		}
 
 		return false
-	}
+	})
 }
 
 // CallExpr check if the given ast.CallExpr matches the given conditions.
 func CallExpr(fun NodeCondition, args NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.CallExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.CallExpr); ok {
-			return fun(e.Fun) && args(toNodes(e.Args))
+			return fun.match(ev, e.Fun) && matchNodes(ev, args, e.Args)
 		}
 
 		return false
-	}
+	})
 }
 
 // StarExpr check if the given ast.TypeAssertExpr matches the given conditions.
 func StarExpr(x NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.StarExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.StarExpr); ok {
-			return x(e.X)
+			return x.match(ev, e.X)
 		}
 
 		return false
-	}
+	})
 }
 
 // UnaryExpr check if the given ast.UnaryExpr matches the given conditions.
 func UnaryExpr(x NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.UnaryExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.UnaryExpr); ok {
-			return x(e.X)
+			return x.match(ev, e.X)
 		}
 
 		return false
-	}
+	})
 }
 
 // BinaryExpr check if the given ast.BinaryExpr matches the given conditions.
 func BinaryExpr(x, y NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.BinaryExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.BinaryExpr); ok {
-			return x(e.X) && y(e.Y)
+			return x.match(ev, e.X) && y.match(ev, e.Y)
 		}
 
 		return false
-	}
+	})
 }
 
 // KeyValueExpr check if the given ast.KeyValueExpr matches the given conditions.
 func KeyValueExpr(k, v NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.KeyValueExpr), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.KeyValueExpr); ok {
-			return k(e.Key) && v(e.Value)
+			return k.match(ev, e.Key) && v.match(ev, e.Value)
 		}
 
 		return false
-	}
+	})
 }
 
 /**************************************************************************
@@ -234,68 +321,68 @@ func KeyValueExpr(k, v NodeCondition) NodeCondition {
 
 // ArrayType check if the given ast.ArrayType matches the given conditions.
 func ArrayType(elt, l NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.ArrayType), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.ArrayType); ok {
-			return elt(e.Elt) && l(e.Len)
+			return elt.match(ev, e.Elt) && l.match(ev, e.Len)
 		}
 
 		return false
-	}
+	})
 }
 
 // StructType check if the given ast.StructType matches the given conditions.
 func StructType(fields NodeCondition, incomplete BoolCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.StructType), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.StructType); ok {
-			return fields(e.Fields) && incomplete(e.Incomplete)
+			return fields.match(ev, e.Fields) && incomplete(e.Incomplete)
 		}
 
 		return false
-	}
+	})
 }
 
 // FuncType check if the given ast.FuncType matches the given conditions.
 func FuncType(params, results NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.FuncType), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.FuncType); ok {
-			return params(e.Params) && results(e.Results)
+			return params.match(ev, e.Params) && results.match(ev, e.Results)
 		}
 
 		return false
-	}
+	})
 }
 
 // InterfaceType check if the given ast.InterfaceType matches the given conditions.
 func InterfaceType(methods NodeCondition, incomplete BoolCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.InterfaceType), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.InterfaceType); ok {
-			return methods(e.Methods) && incomplete(e.Incomplete)
+			return methods.match(ev, e.Methods) && incomplete(e.Incomplete)
 		}
 
 		return false
-	}
+	})
 }
 
 // MapType check if the given ast.MapType matches the given conditions.
 func MapType(k, v NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.MapType), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.MapType); ok {
-			return k(e.Key) && v(e.Value)
+			return k.match(ev, e.Key) && v.match(ev, e.Value)
 		}
 
 		return false
-	}
+	})
 }
 
 // ChanType check if the given ast.ChanType matches the given conditions.
 func ChanType(k NodeCondition, v ChanDirCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.ChanType), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.ChanType); ok {
-			return k(e.Value) && v(e.Dir)
+			return k.match(ev, e.Value) && v(e.Dir)
 		}
 
 		return false
-	}
+	})
 }
 
 /**************************************************************************
@@ -309,222 +396,222 @@ func BadStmt() NodeCondition {
 
 // DeclStmt check if the given ast.DeclStmt matches the given conditions.
 func DeclStmt(decl NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.DeclStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.DeclStmt); ok {
-			return decl(e.Decl)
+			return decl.match(ev, e.Decl)
 		}
 
 		return false
-	}
+	})
 }
 
 // EmptyStmt check if the given ast.EmptyStmt matches the given conditions.
 func EmptyStmt(implicit BoolCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.EmptyStmt), func(_ *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.EmptyStmt); ok {
 			return implicit(e.Implicit)
 		}
 
 		return false
-	}
+	})
 }
 
 // LabeledStmt check if the given ast.LabeledStmt matches the given conditions.
 func LabeledStmt(label, stmt NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.LabeledStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.LabeledStmt); ok {
-			return label(e.Label) && stmt(e.Label)
+			return label.match(ev, e.Label) && stmt.match(ev, e.Label)
 		}
 
 		return false
-	}
+	})
 }
 
 // ExprStmt check if the given ast.ExprStmt matches the given conditions.
 func ExprStmt(x NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.ExprStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.ExprStmt); ok {
-			return x(e.X)
+			return x.match(ev, e.X)
 		}
 
 		return false
-	}
+	})
 }
 
 // SendStmt check if the given ast.SendStmt matches the given conditions.
 func SendStmt(channel, val NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.SendStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.SendStmt); ok {
-			return channel(e.Chan) && val(e.Value)
+			return channel.match(ev, e.Chan) && val.match(ev, e.Value)
 		}
 
 		return false
-	}
+	})
 }
 
 // IncDecStmt check if the given ast.IncDecStmt matches the given conditions.
 func IncDecStmt(x NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.IncDecStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.IncDecStmt); ok {
-			return x(e.X)
+			return x.match(ev, e.X)
 		}
 
 		return false
-	}
+	})
 }
 
 // AssignStmt check if the given ast.AssignStmt matches the given conditions.
 func AssignStmt(lhs, rhs NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.AssignStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.AssignStmt); ok {
-			return lhs(toNodes(e.Lhs)) && rhs(toNodes(e.Rhs))
+			return matchNodes(ev, lhs, e.Lhs) && matchNodes(ev, rhs, e.Rhs)
 		}
 
 		return false
-	}
+	})
 }
 
 // GoStmt check if the given ast.GoStmt matches the given conditions.
 func GoStmt(call NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.GoStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.GoStmt); ok {
-			return call(e.Call)
+			return call.match(ev, e.Call)
 		}
 
 		return false
-	}
+	})
 }
 
 // DeferStmt check if the given ast.DeferStmt matches the given conditions.
 func DeferStmt(call NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.DeferStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.DeferStmt); ok {
-			return call(e.Call)
+			return call.match(ev, e.Call)
 		}
 
 		return false
-	}
+	})
 }
 
 // ReturnStmt check if the given ast.ReturnStmt matches the given conditions.
 func ReturnStmt(results NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.ReturnStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.ReturnStmt); ok {
-			return results(toNodes(e.Results))
+			return matchNodes(ev, results, e.Results)
 		}
 
 		return false
-	}
+	})
 }
 
 // BranchStmt check if the given ast.BranchStmt matches the given conditions.
 func BranchStmt(label NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.BranchStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.BranchStmt); ok {
-			return label(e.Label)
+			return label.match(ev, e.Label)
 		}
 
 		return false
-	}
+	})
 }
 
 // BlockStmt check if the given ast.BranchStmt matches the given conditions.
 func BlockStmt(stmts NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.BlockStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.BlockStmt); ok {
-			return stmts(toNodes(e.List))
+			return matchNodes(ev, stmts, e.List)
 		}
 
 		return false
-	}
+	})
 }
 
 // IfStmt check if the given ast.IfStmt matches the given conditions.
 func IfStmt(init, body, cond, els NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.IfStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.IfStmt); ok {
-			return init(e.Init) && cond(e.Cond) && body(e.Body) && els(e.Else)
+			return init.match(ev, e.Init) && cond.match(ev, e.Cond) && body.match(ev, e.Body) && els.match(ev, e.Else)
 		}
 
 		return false
-	}
+	})
 }
 
 // CaseClause check if the given ast.CaseClause matches the given conditions.
 func CaseClause(list NodesCondition, body NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.CaseClause), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.CaseClause); ok {
-			return list(toNodes(e.List)) && body(toNodes(e.Body))
+			return matchNodes(ev, list, e.List) && matchNodes(ev, body, e.Body)
 		}
 
 		return false
-	}
+	})
 }
 
 // SwitchStmt check if the given ast.SwitchStmt matches the given conditions.
 func SwitchStmt(init, tag, body NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.SwitchStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.SwitchStmt); ok {
-			return init(e.Init) && tag(e.Tag) && body(e.Body)
+			return init.match(ev, e.Init) && tag.match(ev, e.Tag) && body.match(ev, e.Body)
 		}
 
 		return false
-	}
+	})
 }
 
 // TypeSwitchStmt check if the given ast.SwitchStmt matches the given conditions.
 func TypeSwitchStmt(init, assign, body NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.TypeSwitchStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.TypeSwitchStmt); ok {
-			return init(e.Init) && assign(e.Assign) && body(e.Body)
+			return init.match(ev, e.Init) && assign.match(ev, e.Assign) && body.match(ev, e.Body)
 		}
 
 		return false
-	}
+	})
 }
 
 // CommClause check if the given ast.CommClause matches the given conditions.
 func CommClause(comm NodeCondition, body NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.CommClause), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.CommClause); ok {
-			return comm(e.Comm) && body(toNodes(e.Body))
+			return comm.match(ev, e.Comm) && matchNodes(ev, body, e.Body)
 		}
 
 		return false
-	}
+	})
 }
 
 // SelectStmt check if the given ast.SelectStmt matches the given conditions.
 func SelectStmt(body NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.SelectStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.SelectStmt); ok {
-			return body(e.Body)
+			return body.match(ev, e.Body)
 		}
 
 		return false
-	}
+	})
 }
 
 // ForStmt check if the given ast.ForStmt matches the given conditions.
 func ForStmt(init, cond, post, body NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.ForStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.ForStmt); ok {
-			return init(e.Init) && cond(e.Cond) && post(e.Post) && body(e.Body)
+			return init.match(ev, e.Init) && cond.match(ev, e.Cond) && post.match(ev, e.Post) && body.match(ev, e.Body)
 		}
 
 		return false
-	}
+	})
 }
 
 // RangeStmt check if the given ast.RangeStmt matches the given conditions.
 func RangeStmt(k, v, x, body NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.RangeStmt), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.RangeStmt); ok {
-			return k(e.Key) && v(e.Value) && x(e.X) && body(e.Body)
+			return k.match(ev, e.Key) && v.match(ev, e.Value) && x.match(ev, e.X) && body.match(ev, e.Body)
 		}
 
 		return false
-	}
+	})
 }
 
 /**************************************************************************
@@ -538,35 +625,35 @@ func Spec() NodeCondition {
 
 // ImportSpec check if the given ast.ImportSpec matches the given conditions.
 func ImportSpec(doc, name, importPath, comment NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.ImportSpec), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.ImportSpec); ok {
-			return doc(e.Doc) && name(e.Name) && importPath(e.Path) && comment(e.Comment)
+			return doc.match(ev, e.Doc) && name.match(ev, e.Name) && importPath.match(ev, e.Path) && comment.match(ev, e.Comment)
 		}
 
 		return false
-	}
+	})
 }
 
 // ValueSpec check if the given ast.ValueSpec matches the given conditions.
 func ValueSpec(doc, t, comment NodeCondition, names NodesCondition, values NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.ValueSpec), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.ValueSpec); ok {
-			return doc(e.Doc) && names(toNodes(e.Names)) && t(e.Type) && values(toNodes(e.Values)) && comment(e.Comment)
+			return doc.match(ev, e.Doc) && matchNodes(ev, names, e.Names) && t.match(ev, e.Type) && matchNodes(ev, values, e.Values) && comment.match(ev, e.Comment)
 		}
 
 		return false
-	}
+	})
 }
 
 // TypeSpec check if the given ast.TypeSpec matches the given conditions.
 func TypeSpec(doc, name, t, comment NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.TypeSpec), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.TypeSpec); ok {
-			return doc(e.Doc) && name(e.Name) && t(e.Type) && comment(e.Comment)
+			return doc.match(ev, e.Doc) && name.match(ev, e.Name) && t.match(ev, e.Type) && comment.match(ev, e.Comment)
 		}
 
 		return false
-	}
+	})
 }
 
 /**************************************************************************
@@ -580,24 +667,24 @@ func BadDecl() NodeCondition {
 
 // GenDecl check if the given ast.GenDecl matches the given conditions.
 func GenDecl(doc NodeCondition, specs NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.GenDecl), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.GenDecl); ok {
-			return doc(e.Doc) && specs(toNodes(e.Specs))
+			return doc.match(ev, e.Doc) && matchNodes(ev, specs, e.Specs)
 		}
 
 		return false
-	}
+	})
 }
 
 // FuncDecl check if the given ast.FuncDecl matches the given conditions.
 func FuncDecl(doc, recv, name, t, body NodeCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.FuncDecl), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.FuncDecl); ok {
-			return doc(e.Doc) && recv(e.Recv) && name(e.Name) && t(e.Type) && body(e.Body)
+			return doc.match(ev, e.Doc) && recv.match(ev, e.Recv) && name.match(ev, e.Name) && t.match(ev, e.Type) && body.match(ev, e.Body)
 		}
 
 		return false
-	}
+	})
 }
 
 /**************************************************************************
@@ -613,19 +700,19 @@ func File(
 	imports,
 	unresolved NodesCondition,
 	comments NodesCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.File), func(ev *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.File); ok {
-			return doc(e.Doc) &&
-				name(e.Name) &&
-				decls(toNodes(e.Decls)) &&
+			return doc.match(ev, e.Doc) &&
+				name.match(ev, e.Name) &&
+				matchNodes(ev, decls, e.Decls) &&
 				scope(e.Scope) &&
-				imports(toNodes(e.Imports)) &&
-				unresolved(toNodes(e.Unresolved)) &&
-				comments(toNodes(e.Comments))
+				matchNodes(ev, imports, e.Imports) &&
+				matchNodes(ev, unresolved, e.Unresolved) &&
+				matchNodes(ev, comments, e.Comments)
 		}
 
 		return false
-	}
+	})
 }
 
 // Package check if the given ast.Package matches the given conditions.
@@ -634,59 +721,199 @@ func Package(
 	name StringCondition,
 	imports ImportsMapCondition,
 	files FilesMapCondition) NodeCondition {
-	return func(n ast.Node) bool {
+	return typed(new(ast.Package), func(_ *evaluation, n ast.Node) bool {
 		if e, ok := n.(*ast.Package); ok {
 			return scope(e.Scope) && name(e.Name) && imports(e.Imports) && files(e.Files)
 		}
 
 		return false
-	}
+	})
 }
 
 /**************************************************************************
 	custom
 **************************************************************************/
 
+// Func returns a NodeCondition calling f. It may accept any node, unless its kinds are declared by Kinds.
+func Func(f func(n ast.Node) bool) NodeCondition {
+	return NodeCondition{eval: func(_ *evaluation, n ast.Node) bool {
+		return f(n)
+	}}
+}
+
+// NodesFunc returns a NodesCondition calling f, f must not retain the slice.
+func NodesFunc(f func(nodes []ast.Node) bool) NodesCondition {
+	return NodesCondition{eval: func(_ *evaluation, nodes []ast.Node) bool {
+		return f(nodes)
+	}}
+}
+
+// And check if all given conditions match the node. It accepts the kinds all conditions accept.
+func And(conditions ...NodeCondition) NodeCondition {
+	var kinds map[reflect.Type]bool
+
+	for _, c := range conditions {
+		if c.kinds == nil {
+			continue
+		}
+
+		if kinds == nil {
+			kinds = c.kinds
+
+			continue
+		}
+
+		var both = map[reflect.Type]bool{}
+
+		for k := range c.kinds {
+			if kinds[k] {
+				both[k] = true
+			}
+		}
+
+		kinds = both
+	}
+
+	return NodeCondition{kinds: kinds, eval: func(ev *evaluation, n ast.Node) bool {
+		for _, c := range conditions {
+			if !c.match(ev, n) {
+				return false
+			}
+		}
+
+		return true
+	}}
+}
+
+// Or check if any of the given conditions matches the node. It accepts the kinds of all conditions,
+// or any node if one of them may accept any node.
+func Or(conditions ...NodeCondition) NodeCondition {
+	var kinds = map[reflect.Type]bool{}
+
+	for _, c := range conditions {
+		if c.kinds == nil {
+			kinds = nil
+
+			break
+		}
+
+		for k := range c.kinds {
+			kinds[k] = true
+		}
+	}
+
+	return NodeCondition{kinds: kinds, eval: func(ev *evaluation, n ast.Node) bool {
+		for _, c := range conditions {
+			if c.match(ev, n) {
+				return true
+			}
+		}
+
+		return false
+	}}
+}
+
+// Not check if the given condition does not match the node. It may accept any node.
+func Not(c NodeCondition) NodeCondition {
+	return NodeCondition{eval: func(ev *evaluation, n ast.Node) bool {
+		return !c.match(ev, n)
+	}}
+}
+
+// First check if the first node matches the given condition, it matches an empty list.
 func First(c NodeCondition) NodesCondition {
-	return func(nodes []ast.Node) bool {
+	return NodesCondition{label: "First", eval: func(ev *evaluation, nodes []ast.Node) bool {
 		if len(nodes) == 0 {
 			return true
 		}
 
-		return c(nodes[0])
-	}
+		return c.match(ev, nodes[0])
+	}}
 }
 
+// Last check if the last node matches the given condition, it matches an empty list.
 func Last(c NodeCondition) NodesCondition {
-	return func(nodes []ast.Node) bool {
+	return NodesCondition{label: "Last", eval: func(ev *evaluation, nodes []ast.Node) bool {
 		if len(nodes) == 0 {
 			return true
 		}
 
-		return c(nodes[len(nodes)-1])
-	}
+		return c.match(ev, nodes[len(nodes)-1])
+	}}
 }
 
 // Type check if the given values type matches the requested one.
 func Type(t interface{}) NodeCondition {
-	wantType := reflect.ValueOf(t).Type()
+	wantType := reflect.TypeOf(t)
 
-	return func(n ast.Node) bool {
-		return wantType.AssignableTo(reflect.ValueOf(n).Type())
+	c := NodeCondition{
+		eval: func(_ *evaluation, n ast.Node) bool {
+			return reflect.TypeOf(n) == wantType
+		},
+		label: "Type(" + kindName(t) + ")",
+	}
+
+	if n, ok := t.(ast.Node); ok {
+		c.kinds = kindSet([]ast.Node{n})
+	}
+
+	return c
+}
+
+// Is check if the given node is of type T, e.g. Is[*ast.CallExpr]().
+// Unlike Type it uses a type assertion, so T may also be an interface like ast.Expr.
+func Is[T ast.Node]() NodeCondition {
+	var (
+		zero T
+		c    = NodeCondition{
+			eval: func(_ *evaluation, n ast.Node) bool {
+				_, ok := n.(T)
+
+				return ok
+			},
+			label: "Is(" + reflect.TypeOf((*T)(nil)).Elem().String() + ")",
+		}
+	)
+
+	if ast.Node(zero) != nil {
+		c.kinds = kindSet([]ast.Node{zero})
+	}
+
+	return c
+}
+
+// Exactly check if the given int equals n.
+func Exactly(n int) IntCondition {
+	return func(i int) bool {
+		return i == n
+	}
+}
+
+// AtLeast check if the given int is greater than or equal to n.
+func AtLeast(n int) IntCondition {
+	return func(i int) bool {
+		return i >= n
+	}
+}
+
+// AtMost check if the given int is less than or equal to n.
+func AtMost(n int) IntCondition {
+	return func(i int) bool {
+		return i <= n
 	}
 }
 
 // IgnoreNode always returns true.
 func IgnoreNode() NodeCondition {
-	return func(n ast.Node) bool {
+	return NodeCondition{eval: func(*evaluation, ast.Node) bool {
 		return true
-	}
+	}}
 }
 
 func IgnoreNodes() NodesCondition {
-	return func(n []ast.Node) bool {
+	return NodesCondition{eval: func(*evaluation, []ast.Node) bool {
 		return true
-	}
+	}}
 }
 
 func IgnoreScope() ScopeCondition {
@@ -695,28 +922,29 @@ func IgnoreScope() ScopeCondition {
 	}
 }
 
-func toNodes(e interface{}) []ast.Node {
-	var (
-		n  []ast.Node
-		ev = reflect.ValueOf(e)
-	)
+// nodesPool holds the buffers passed to NodesConditions.
+var nodesPool = sync.Pool{New: func() interface{} { return new([]ast.Node) }}
 
-	for i := 0; i < ev.Len(); i++ {
-		switch v := ev.Index(i).Interface().(type) {
-		case *ast.Ident:
-			n = append(n, v)
-		case *ast.ImportSpec:
-			n = append(n, v)
-		case *ast.CommentGroup:
-			n = append(n, v)
-		case ast.Expr:
-			n = append(n, v)
-		case ast.Decl:
-			n = append(n, v)
-		case ast.Stmt:
-			n = append(n, v)
+// matchNodes passes the elements of a slice field to the given condition, without reflection and allocation.
+// The buffer is reused once the condition returned, so NodesConditions must not retain the slice.
+func matchNodes[T ast.Node](ev *evaluation, c NodesCondition, s []T) bool {
+	if len(s) == 0 {
+		return c.match(ev, nil)
+	}
+
+	var buf = nodesPool.Get().(*[]ast.Node)
+
+	for _, e := range s {
+		if n := ast.Node(e); n != nil {
+			*buf = append(*buf, n)
 		}
 	}
 
-	return n
+	var res = c.match(ev, *buf)
+
+	clear(*buf)
+	*buf = (*buf)[:0]
+	nodesPool.Put(buf)
+
+	return res
 }
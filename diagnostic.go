@@ -0,0 +1,214 @@
+package asterisk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// ErrUnknownSeverity is returned when parsing an unknown severity name.
+var ErrUnknownSeverity = errors.New("unknown severity")
+
+const fingerprintSize = 16
+
+// Severity classifies how serious a Diagnostic is.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// MarshalText encodes the severity by its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes the severity from its name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for severity, name := range severityNames {
+		if name == strings.ToLower(string(text)) {
+			*s = severity
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownSeverity, text)
+}
+
+// Diagnostic describes a finding of a rule.
+type Diagnostic struct {
+	Rule     string
+	Severity Severity
+	Message  string
+	Pos      token.Position
+	End      token.Position
+	// Related holds further positions that are relevant for the finding.
+	Related []token.Position
+	// Edits fix the finding.
+	Edits []TextEdit
+	// Fingerprint identifies the finding independent of its position, see Fingerprint.
+	Fingerprint string
+}
+
+// NewDiagnostic creates a Diagnostic spanning the given node.
+func NewDiagnostic(fileSet *token.FileSet, node ast.Node, rule string, severity Severity, message string) Diagnostic {
+	return Diagnostic{
+		Rule:        rule,
+		Severity:    severity,
+		Message:     message,
+		Pos:         fileSet.Position(node.Pos()),
+		End:         fileSet.Position(node.End()),
+		Fingerprint: Fingerprint(rule, node),
+	}
+}
+
+// Fingerprint identifies a finding of a rule by the source text of the matched node.
+// The node is printed without positions, so the fingerprint does not change if the node moves or
+// its formatting changes.
+func Fingerprint(rule string, node ast.Node) string {
+	var buf bytes.Buffer
+
+	_ = printer.Fprint(&buf, token.NewFileSet(), Clone(node, ResetPositions()))
+
+	var sum = sha256.Sum256(append([]byte(rule+"\x00"), buf.Bytes()...))
+
+	return hex.EncodeToString(sum[:fingerprintSize])
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%v: %v: %v (%v)", d.Pos, d.Severity, d.Message, d.Rule)
+}
+
+// Message is a diagnostic message template that references selections by their key,
+// e.g. "use {{.level}} instead of {{.method}}".
+type Message struct {
+	tmpl *template.Template
+}
+
+// ParseMessage parses the given message template.
+func ParseMessage(text string) (*Message, error) {
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{tmpl: tmpl}, nil
+}
+
+// Keys returns the selection keys the message refers to, in order of their first use.
+// Fields within range and with blocks are not keys, since they refer to another value.
+func (m *Message) Keys() []string {
+	var keys []string
+
+	var visit func(n parse.Node)
+	visit = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n != nil {
+				for _, c := range n.Nodes {
+					visit(c)
+				}
+			}
+		case *parse.ActionNode:
+			visit(n.Pipe)
+		case *parse.IfNode:
+			visit(n.Pipe)
+			visit(n.List)
+			visit(n.ElseList)
+		case *parse.RangeNode:
+			visit(n.Pipe)
+		case *parse.WithNode:
+			visit(n.Pipe)
+		case *parse.TemplateNode:
+			visit(n.Pipe)
+		case *parse.PipeNode:
+			if n != nil {
+				for _, cmd := range n.Cmds {
+					visit(cmd)
+				}
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				visit(arg)
+			}
+		case *parse.ChainNode:
+			visit(n.Node)
+		case *parse.FieldNode:
+			if !contains(keys, n.Ident[0]) {
+				keys = append(keys, n.Ident[0])
+			}
+		}
+	}
+
+	if m.tmpl.Tree != nil {
+		visit(m.tmpl.Tree.Root)
+	}
+
+	return keys
+}
+
+// Render renders the message using the source text of each selected node.
+// Multiple nodes selected for one key are joined by comma.
+func (m *Message) Render(fileSet *token.FileSet, s NodeSelections) (string, error) {
+	var data = make(map[string]string, len(s))
+
+	for key, nodes := range s {
+		var texts = make([]string, len(nodes))
+		for i := range nodes {
+			text, err := SourceText(fileSet, **nodes[i])
+			if err != nil {
+				return "", err
+			}
+
+			texts[i] = text
+		}
+
+		data[key] = strings.Join(texts, ", ")
+	}
+
+	var sb strings.Builder
+	if err := m.tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// SourceText returns the source text of the given node.
+func SourceText(fileSet *token.FileSet, node ast.Node) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fileSet, node); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
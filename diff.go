@@ -0,0 +1,211 @@
+package asterisk
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDiff is returned for malformed unified diffs.
+var ErrInvalidDiff = errors.New("invalid diff")
+
+const devNull = "/dev/null"
+
+var hunkRegex = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Diff holds the lines added or changed by a unified diff, e.g. the output of "git diff".
+// Its files are resolved against a root directory, by default the working directory.
+type Diff struct {
+	files map[string][]lineRange
+	root  string
+}
+
+type lineRange struct {
+	from, to int
+}
+
+// LoadDiff reads a unified diff from the given file, "-" reads from stdin.
+func LoadDiff(file string) (*Diff, error) {
+	if file == "-" {
+		return ParseDiff(os.Stdin)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return ParseDiff(f)
+}
+
+// ParseDiff reads the changed lines of the new files from a unified diff.
+// Removed lines are not recorded, since there is no code left to report on.
+func ParseDiff(r io.Reader) (*Diff, error) {
+	var (
+		d                        = &Diff{files: map[string][]lineRange{}}
+		scanner                  = bufio.NewScanner(r)
+		file                     string
+		line, oldCount, newCount int
+		lineNumber               int
+	)
+
+	scanner.Buffer(nil, 1024*1024)
+
+	for scanner.Scan() {
+		var text = scanner.Text()
+
+		lineNumber++
+
+		if oldCount > 0 || newCount > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				d.add(file, line)
+				line++
+				newCount--
+			case strings.HasPrefix(text, "-"):
+				oldCount--
+			case strings.HasPrefix(text, " "), text == "":
+				line++
+				newCount--
+				oldCount--
+			case strings.HasPrefix(text, `\`):
+			default:
+				return nil, fmt.Errorf("%w: line %v: unexpected %q in hunk", ErrInvalidDiff, lineNumber, text)
+			}
+
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = diffFile(strings.TrimPrefix(text, "+++ "))
+		case strings.HasPrefix(text, "@@ "):
+			m := hunkRegex.FindStringSubmatch(text)
+			if m == nil {
+				return nil, fmt.Errorf("%w: line %v: malformed hunk header %q", ErrInvalidDiff, lineNumber, text)
+			}
+
+			oldCount, line, newCount = hunkCount(m[1]), hunkCount(m[2]), hunkCount(m[3])
+		}
+	}
+
+	return d, scanner.Err()
+}
+
+// diffFile returns the slash separated path of a file header, without the "b/" prefix git adds.
+func diffFile(header string) string {
+	if i := strings.IndexByte(header, '\t'); i >= 0 {
+		header = header[:i]
+	}
+
+	if header == devNull {
+		return ""
+	}
+
+	return slashPath(strings.TrimPrefix(header, "b/"))
+}
+
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+
+	n, _ := strconv.Atoi(s)
+
+	return n
+}
+
+func (d *Diff) add(file string, line int) {
+	if file == "" {
+		return
+	}
+
+	var ranges = d.files[file]
+	if n := len(ranges); n > 0 && ranges[n-1].to == line-1 {
+		ranges[n-1].to = line
+
+		return
+	}
+
+	d.files[file] = append(ranges, lineRange{from: line, to: line})
+}
+
+// SetRoot resolves the files of the diff against the given directory, e.g. the root of the repository
+// "git diff" was run in.
+func (d *Diff) SetRoot(dir string) error {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	d.root = root
+
+	return nil
+}
+
+// Changed reports whether any line between from and to of the given file was changed.
+// The path of the file relative to the root of the diff has to equal the path in the diff.
+func (d *Diff) Changed(file string, from, to int) bool {
+	name, ok := d.relative(file)
+	if !ok {
+		return false
+	}
+
+	for _, r := range d.files[name] {
+		if r.from <= to && from <= r.to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// relative returns the slash separated path of the file relative to the root of the diff.
+// It fails for files outside of the root.
+func (d *Diff) relative(file string) (string, bool) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+
+	var root = d.root
+	if root == "" {
+		if root, err = os.Getwd(); err != nil {
+			return "", false
+		}
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return slashPath(rel), true
+}
+
+// Filter returns a ReportFunc that passes only diagnostics on changed lines to report.
+// Since rewrites are carried by diagnostics, findings outside the diff are neither reported nor fixed.
+func (d *Diff) Filter(report ReportFunc) ReportFunc {
+	return func(diagnostic Diagnostic) {
+		var end = diagnostic.End.Line
+		if end < diagnostic.Pos.Line {
+			end = diagnostic.Pos.Line
+		}
+
+		if d.Changed(diagnostic.Pos.Filename, diagnostic.Pos.Line, end) {
+			report(diagnostic)
+		}
+	}
+}
+
+func slashPath(file string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(file)), "./")
+}
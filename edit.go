@@ -0,0 +1,54 @@
+package asterisk
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// ErrEditOutOfRange is returned when an edit does not fit into the source it is applied to.
+var ErrEditOutOfRange = errors.New("edit out of range")
+
+// TextEdit replaces the source text between Pos and End by NewText.
+type TextEdit struct {
+	Pos     token.Position
+	End     token.Position
+	NewText string
+}
+
+// ApplyEdits applies the given edits to src.
+// Edits that overlap an edit that was already applied are skipped and returned.
+func ApplyEdits(src []byte, edits []TextEdit) ([]byte, []TextEdit, error) {
+	var (
+		sorted  = make([]TextEdit, len(edits))
+		out     []byte
+		skipped []TextEdit
+		offset  int
+	)
+
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Pos.Offset < sorted[j].Pos.Offset
+	})
+
+	for _, edit := range sorted {
+		if edit.Pos.Offset > edit.End.Offset || edit.End.Offset > len(src) {
+			return nil, nil, fmt.Errorf("%w: %v-%v", ErrEditOutOfRange, edit.Pos.Offset, edit.End.Offset)
+		}
+
+		if edit.Pos.Offset < offset {
+			skipped = append(skipped, edit)
+
+			continue
+		}
+
+		out = append(out, src[offset:edit.Pos.Offset]...)
+		out = append(out, edit.NewText...)
+		offset = edit.End.Offset
+	}
+
+	out = append(out, src[offset:]...)
+
+	return out, skipped, nil
+}
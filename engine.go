@@ -0,0 +1,270 @@
+package asterisk
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// MatcherFactory returns the matchers for the given file, they pass their diagnostics to report.
+// It is called once per file, so every walk has its own matcher state.
+type MatcherFactory func(fileSet *token.FileSet, file *ast.File, report ReportFunc) PatternMatchers
+
+// WalkOptionFactory returns the walk options of the given file.
+// It is called once per file, so the options may hold state of a single walk.
+type WalkOptionFactory func(fileSet *token.FileSet, file *ast.File) []WalkOption
+
+// EngineOption configures an Engine.
+type EngineOption func(*Engine)
+
+// ErrSharedVerifier is the error of all files of an Engine that shares a Verifier between its walks.
+var ErrSharedVerifier = errors.New("a Verifier cannot be shared by the walks of an Engine, use WithWalkOptionFactory")
+
+// Engine walks many files concurrently, each file with its own matchers.
+type Engine struct {
+	factory      MatcherFactory
+	workers      int
+	suppressions bool
+	walkOptions  []WalkOption
+	walkFactory  WalkOptionFactory
+	maxFileSize  int64
+	err          error
+}
+
+// FileResult holds the outcome of walking a single file.
+type FileResult struct {
+	Name string
+	// Src is the source of the file, if it was read by the Engine.
+	Src         []byte
+	Diagnostics []Diagnostic
+	// Err is the error of the file, a *LimitError if it was skipped or its walk was aborted by a limit.
+	// The diagnostics reported before a walk was aborted by a limit are kept.
+	Err error
+}
+
+// NewEngine returns an Engine creating the matchers of each file using the given factory.
+func NewEngine(factory MatcherFactory, opts ...EngineOption) *Engine {
+	var e = &Engine{factory: factory, workers: runtime.GOMAXPROCS(0)}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Workers sets the number of files walked concurrently, it defaults to GOMAXPROCS.
+func Workers(n int) EngineOption {
+	return func(e *Engine) {
+		if n > 0 {
+			e.workers = n
+		}
+	}
+}
+
+// WithSuppressions applies the inline suppression directives of the files. Unused directives that refer to
+// the name of a matcher, or to all rules, are reported as diagnostics.
+func WithSuppressions() EngineOption {
+	return func(e *Engine) {
+		e.suppressions = true
+	}
+}
+
+// MaxFileSize skips files larger than the given number of bytes, they fail with a *LimitError.
+func MaxFileSize(bytes int64) EngineOption {
+	return func(e *Engine) {
+		e.maxFileSize = bytes
+	}
+}
+
+// WithWalkOptions passes the given options to each Walk. The options are shared by all workers, so an Interceptor
+// must be safe for concurrent use. Verify is rejected, all files fail with ErrSharedVerifier.
+// Options holding state of a walk are created per file using WithWalkOptionFactory.
+func WithWalkOptions(opts ...WalkOption) EngineOption {
+	return func(e *Engine) {
+		for _, opt := range opts {
+			var cfg walkConfig
+			if opt(&cfg); cfg.verifier != nil {
+				e.err = ErrSharedVerifier
+			}
+		}
+
+		e.walkOptions = append(e.walkOptions, opts...)
+	}
+}
+
+// WithWalkOptionFactory passes the options returned by the given factory for each file to its Walk,
+// after the options passed by WithWalkOptions.
+func WithWalkOptionFactory(factory WalkOptionFactory) EngineOption {
+	return func(e *Engine) {
+		e.walkFactory = factory
+	}
+}
+
+// Run walks the given files, which have to be parsed with comments if suppressions are applied.
+// The results are ordered like the files, the diagnostics of each file are ordered by position.
+func (e *Engine) Run(fileSet *token.FileSet, files []*ast.File) []FileResult {
+	return e.RunContext(context.Background(), fileSet, files)
+}
+
+// RunContext is like Run, files not walked before the context is done fail with the error of the context.
+func (e *Engine) RunContext(ctx context.Context, fileSet *token.FileSet, files []*ast.File) []FileResult {
+	var names = make([]string, len(files))
+	for i, f := range files {
+		names[i] = fileSet.Position(f.Pos()).Filename
+	}
+
+	return e.run(ctx, names, func(i int) FileResult {
+		var r = FileResult{Name: names[i]}
+
+		if r.Err = e.checkFileSize(int64(fileSet.File(files[i].Pos()).Size())); r.Err != nil {
+			return r
+		}
+
+		r.Diagnostics, r.Err = e.walk(ctx, fileSet, files[i])
+
+		return r
+	})
+}
+
+// RunFiles reads, parses and walks the given files.
+// The results are ordered like the files, the diagnostics of each file are ordered by position.
+func (e *Engine) RunFiles(names []string) []FileResult {
+	return e.RunFilesContext(context.Background(), names)
+}
+
+// RunFilesContext is like RunFiles, files not walked before the context is done fail with the error of the context.
+func (e *Engine) RunFilesContext(ctx context.Context, names []string) []FileResult {
+	return e.run(ctx, names, func(i int) FileResult {
+		var r = FileResult{Name: names[i]}
+
+		info, err := os.Stat(names[i])
+		if err != nil {
+			r.Err = err
+
+			return r
+		}
+
+		if r.Err = e.checkFileSize(info.Size()); r.Err != nil {
+			return r
+		}
+
+		if r.Src, r.Err = os.ReadFile(names[i]); r.Err != nil {
+			return r
+		}
+
+		var fileSet = token.NewFileSet()
+
+		f, err := parser.ParseFile(fileSet, names[i], r.Src, parser.ParseComments)
+		if err != nil {
+			r.Err = err
+
+			return r
+		}
+
+		r.Diagnostics, r.Err = e.walk(ctx, fileSet, f)
+
+		return r
+	})
+}
+
+func (e *Engine) checkFileSize(size int64) error {
+	if e.maxFileSize > 0 && size > e.maxFileSize {
+		return &LimitError{Limit: LimitFileSize, Max: e.maxFileSize}
+	}
+
+	return nil
+}
+
+// run calls walk for the indexes of the given file names using the workers of the Engine.
+// Once the context is done, the remaining files fail with its error.
+func (e *Engine) run(ctx context.Context, names []string, walk func(i int) FileResult) []FileResult {
+	var (
+		n       = len(names)
+		results = make([]FileResult, n)
+		indexes = make(chan int)
+		wg      sync.WaitGroup
+	)
+
+	for w := 0; w < min(e.workers, n); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i] = FileResult{Name: names[i], Err: err}
+
+					continue
+				}
+
+				results[i] = walk(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// walk returns the diagnostics of the given file. If the walk was aborted by a limit,
+// the diagnostics reported before are returned along with the *LimitError.
+func (e *Engine) walk(ctx context.Context, fileSet *token.FileSet, f *ast.File) ([]Diagnostic, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	var (
+		diagnostics  []Diagnostic
+		suppressions Suppressions
+		report       ReportFunc = func(d Diagnostic) {
+			diagnostics = append(diagnostics, d)
+		}
+	)
+
+	if e.suppressions {
+		suppressions = ParseSuppressions(fileSet, f)
+		report = suppressions.Filter(report)
+	}
+
+	var (
+		pms  = e.factory(fileSet, f, report)
+		opts = append([]WalkOption{WithFileSet(fileSet), WithContext(ctx)}, e.walkOptions...)
+	)
+
+	if e.walkFactory != nil {
+		opts = append(opts, e.walkFactory(fileSet, f)...)
+	}
+
+	var err = Walk(f, pms, opts...)
+
+	if err != nil && !errors.Is(err, ErrLimitExceeded) {
+		return nil, err
+	}
+
+	// an aborted walk did not see all nodes, so unused suppressions may still be used.
+	for _, s := range suppressions.Unused() {
+		if err == nil && s.refersTo(pms) {
+			diagnostics = append(diagnostics, s.Diagnostic())
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Pos.Offset < diagnostics[j].Pos.Offset
+	})
+
+	return diagnostics, err
+}
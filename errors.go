@@ -0,0 +1,53 @@
+package asterisk
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// MatchError describes a failure of processing a match.
+type MatchError struct {
+	// Rule is the name of the failing Matcher.
+	Rule string
+	// Node is the first node of the failing match.
+	Node ast.Node
+	// Position is the resolved position of Node, it is only valid if a FileSet was passed to Walk.
+	Position token.Position
+	Err      error
+}
+
+func (e *MatchError) Error() string {
+	var msg = e.Err.Error()
+	if e.Rule != "" {
+		msg = fmt.Sprintf("%v: %v", e.Rule, msg)
+	}
+
+	if e.Position.IsValid() {
+		return fmt.Sprintf("%v: %v", e.Position, msg)
+	}
+
+	return fmt.Sprintf("pos %v: %v", e.Node.Pos(), msg)
+}
+
+// Unwrap returns the error returned by the match processing.
+func (e *MatchError) Unwrap() error {
+	return e.Err
+}
+
+// MatchErrors aggregates the errors of all failing matches.
+type MatchErrors []*MatchError
+
+func (e MatchErrors) Error() string {
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintf(&sb, "%v match(es) failed:", len(e))
+
+	for _, err := range e {
+		sb.WriteString("\n\t")
+		sb.WriteString(err.Error())
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,124 @@
+package asterisk
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"iter"
+)
+
+// Match is a match found by FindFirst, FindN, All or Stream.
+type Match struct {
+	// Rule is the name of the Matcher.
+	Rule string
+	// Node is the first node of the match.
+	Node ast.Node
+	// Position is the resolved position of Node, it is only valid if a FileSet was passed.
+	Position token.Position
+	// Selections holds the nodes the conditions selected during the match.
+	Selections NodeSelections
+}
+
+// FindFirst returns the first match of the given matchers, or nil if there is none.
+// Matches are not processed, the walk stops at the first match.
+func FindFirst(f ast.Node, pms PatternMatchers, opts ...WalkOption) (*Match, error) {
+	matches, err := FindN(f, pms, 1, opts...)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+
+	return &matches[0], nil
+}
+
+// FindN returns up to n matches of the given matchers in the order they were found, n < 0 returns all matches.
+// Matches are not processed, the walk stops once n matches were found.
+func FindN(f ast.Node, pms PatternMatchers, n int, opts ...WalkOption) ([]Match, error) {
+	var matches []Match
+
+	if n == 0 {
+		return nil, nil
+	}
+
+	for m, err := range find(f, pms, opts) {
+		if err != nil {
+			return matches, err
+		}
+
+		matches = append(matches, m)
+
+		if len(matches) == n {
+			break
+		}
+	}
+
+	return matches, nil
+}
+
+// All returns an iterator over the matches of the pattern in the given node.
+// Matches are not processed, the walk ends when the loop is left. If the walk fails, e.g. because
+// its context is done, the error is yielded last.
+func All(f ast.Node, pattern *Pattern, opts ...WalkOption) iter.Seq2[Match, error] {
+	return find(f, PatternMatchers{pattern.Matcher()}, opts)
+}
+
+// Stream walks the given files one after another and sends the matches of the pattern to the returned channel,
+// which is closed once all files were walked or the context is done. The walk does not continue
+// before a match was received, so callers have to cancel the context if they stop receiving early.
+// Walks can only fail by the context, so callers check its error to tell a canceled stream from a complete one.
+func Stream(ctx context.Context, fileSet *token.FileSet, files []*ast.File, pattern *Pattern) <-chan Match {
+	var matches = make(chan Match)
+
+	go func() {
+		defer close(matches)
+
+		for _, f := range files {
+			for m, err := range All(f, pattern, WithFileSet(fileSet), WithContext(ctx)) {
+				if err != nil {
+					return
+				}
+
+				select {
+				case matches <- m:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return matches
+}
+
+// find walks the given node and yields the matches of the matchers without processing them,
+// followed by the error of the walk if it failed.
+func find(f ast.Node, pms PatternMatchers, opts []WalkOption) iter.Seq2[Match, error] {
+	return func(yield func(Match, error) bool) {
+		var (
+			cfg     walkConfig
+			stopped bool
+		)
+
+		for _, opt := range opts {
+			opt(&cfg)
+		}
+
+		var walkOpts = append(opts[:len(opts):len(opts)], Intercept(func(pm *Matcher, node ast.Node, s NodeSelections, _ func() error) error {
+			var m = Match{Rule: pm.Name(), Node: node, Selections: s}
+			if cfg.fileSet != nil {
+				m.Position = cfg.fileSet.Position(node.Pos())
+			}
+
+			if !yield(m, nil) {
+				stopped = true
+
+				return Stop
+			}
+
+			return nil
+		}))
+
+		if err := Walk(f, pms, walkOpts...); err != nil && !stopped {
+			yield(Match{}, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package formatting provides rules checking the blank lines between statements, with fixes.
+package formatting
+
+import (
+	"go/ast"
+
+	"github.com/Oppodelldog/asterisk"
+)
+
+const category = "formatting"
+
+// Rules returns the compiled formatting rules:
+//
+//	whitespace-before-return  a return that follows at least two statements needs a blank line before it
+//	cuddled-declaration       a declaration that follows a statement needs a blank line before it
+//	leading-blank-lines       a block or case clause must not start with blank lines
+//	trailing-blank-lines      a block must not end with blank lines
+func Rules() asterisk.Rules {
+	var rules = asterisk.Rules{
+		{
+			ID:    "whitespace-before-return",
+			Title: "Blank line before return",
+			Description: "A return statement that follows at least two statements is separated from them by a blank line, " +
+				"so the exit of a block stands out.",
+			Category: category,
+			Tags:     []string{"whitespace", "wsl"},
+			Examples: asterisk.Examples{Good: []string{"a++\na--\n\nreturn a"}, Bad: []string{"a++\na--\nreturn a"}},
+			Message:  "missing blank line before {{.node}}",
+			Severity: asterisk.SeverityWarning,
+			Check: &asterisk.Check{
+				Condition: func(p *asterisk.Positions) asterisk.NodeCondition {
+					return cuddled(p, asterisk.ReturnStmt(asterisk.IgnoreNodes()), 2)
+				},
+				Fix: (*asterisk.Positions).InsertBlankLineBefore,
+			},
+		},
+		{
+			ID:          "cuddled-declaration",
+			Title:       "Blank line before declarations",
+			Description: "A declaration statement is separated from a preceding statement by a blank line.",
+			Category:    category,
+			Tags:        []string{"whitespace", "wsl"},
+			Examples:    asterisk.Examples{Good: []string{"a := 1\n\nvar b = 2"}, Bad: []string{"a := 1\nvar b = 2"}},
+			Message:     "missing blank line before {{.node}}",
+			Severity:    asterisk.SeverityWarning,
+			Check: &asterisk.Check{
+				Condition: func(p *asterisk.Positions) asterisk.NodeCondition {
+					return cuddled(p, asterisk.DeclStmt(asterisk.IgnoreNode()), 1)
+				},
+				Fix: (*asterisk.Positions).InsertBlankLineBefore,
+			},
+		},
+		{
+			ID:          "leading-blank-lines",
+			Title:       "No leading blank lines",
+			Description: "A block or case clause does not start with blank lines.",
+			Category:    category,
+			Tags:        []string{"whitespace"},
+			Examples:    asterisk.Examples{Good: []string{"if ok {\n\treturn\n}"}, Bad: []string{"if ok {\n\n\treturn\n}"}},
+			Message:     "unnecessary blank line before {{.node}}",
+			Severity:    asterisk.SeverityWarning,
+			Check: &asterisk.Check{
+				Condition: func(p *asterisk.Positions) asterisk.NodeCondition {
+					return asterisk.And(p.Index(asterisk.Exactly(0)), p.BlankLinesBefore(asterisk.AtLeast(1)))
+				},
+				Fix: (*asterisk.Positions).DeleteBlankLinesBefore,
+			},
+		},
+		{
+			ID:          "trailing-blank-lines",
+			Title:       "No trailing blank lines",
+			Description: "A block does not end with blank lines.",
+			Category:    category,
+			Tags:        []string{"whitespace"},
+			Examples:    asterisk.Examples{Good: []string{"if ok {\n\treturn\n}"}, Bad: []string{"if ok {\n\treturn\n\n}"}},
+			Message:     "unnecessary blank line after {{.node}}",
+			Severity:    asterisk.SeverityWarning,
+			Check: &asterisk.Check{
+				Condition: func(p *asterisk.Positions) asterisk.NodeCondition {
+					return asterisk.And(
+						p.Last(),
+						p.Parent(asterisk.Type(new(ast.BlockStmt))),
+						p.BlankLinesAfter(asterisk.AtLeast(1)),
+					)
+				},
+				Fix: (*asterisk.Positions).DeleteBlankLinesAfter,
+			},
+		},
+	}
+
+	if err := rules.Compile(); err != nil {
+		panic(err)
+	}
+
+	return rules
+}
+
+// cuddled matches statements directly following the previous statement on the next line,
+// if at least minIndex statements precede them.
+func cuddled(p *asterisk.Positions, stmt asterisk.NodeCondition, minIndex int) asterisk.NodeCondition {
+	return asterisk.And(
+		stmt,
+		p.Index(asterisk.AtLeast(minIndex)),
+		p.BlankLinesBefore(asterisk.Exactly(0)),
+		asterisk.Not(p.OnSameLine(asterisk.IgnoreNode())),
+	)
+}
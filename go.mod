@@ -1,3 +1,9 @@
 module github.com/Oppodelldog/asterisk
 
-go 1.14
+go 1.23.0
+
+require (
+	github.com/sergi/go-diff v1.1.0
+	golang.org/x/tools v0.33.0
+	gopkg.in/yaml.v3 v3.0.1
+)
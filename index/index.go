@@ -0,0 +1,239 @@
+// Package index records which node kinds, identifiers, selector names and literals the files of a code base contain,
+// so repeated queries skip files that cannot match without parsing them.
+//
+// Records are keyed by the hash of the file content. Updating an index parses only new and changed files,
+// files with equal content share a record.
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/Oppodelldog/asterisk"
+)
+
+const version = 2
+
+// ErrVersion is returned by Load for indexes written by an incompatible version.
+var ErrVersion = errors.New("unsupported index version")
+
+// Index maps files to the records of their content.
+type Index struct {
+	Version int `json:"version"`
+	// Files maps the slash separated file names to the hashes of their content.
+	Files map[string]string `json:"files"`
+	// Records holds the records by content hash.
+	Records map[string]*Record `json:"records"`
+}
+
+// Record holds the sorted, distinct node kinds, identifiers, selector names and literal values of a file.
+// Identifiers do not include selector names.
+type Record struct {
+	Kinds     []string `json:"kinds"`
+	Idents    []string `json:"idents,omitempty"`
+	Selectors []string `json:"selectors,omitempty"`
+	Literals  []string `json:"literals,omitempty"`
+	// Suppressions is set if the file contains suppression directives.
+	Suppressions bool `json:"suppressions,omitempty"`
+}
+
+// Stats counts the files processed by Update.
+type Stats struct {
+	Indexed, Unchanged, Removed int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%v indexed, %v unchanged, %v removed", s.Indexed, s.Unchanged, s.Removed)
+}
+
+// New returns an empty Index.
+func New() *Index {
+	return &Index{Version: version, Files: map[string]string{}, Records: map[string]*Record{}}
+}
+
+// Load reads an Index from the given file.
+func Load(file string) (*Index, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, err
+	}
+
+	if idx.Version != version {
+		return nil, fmt.Errorf("%w: %v", ErrVersion, idx.Version)
+	}
+
+	return &idx, nil
+}
+
+// Save writes the Index to the given file.
+func (idx *Index) Save(file string) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0600)
+}
+
+// Update indexes the given files, files whose content did not change since they were indexed are not parsed.
+// Files that are not given are kept, so parts of a code base can be indexed on their own.
+// Files that do not exist any more are removed from the Index.
+func (idx *Index) Update(files []string) (Stats, error) {
+	var stats Stats
+
+	for _, file := range files {
+		var name = filepath.ToSlash(file)
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return stats, err
+		}
+
+		var hash = Hash(src)
+		if _, ok := idx.Records[hash]; ok {
+			if idx.Files[name] == hash {
+				stats.Unchanged++
+			} else {
+				stats.Indexed++
+			}
+
+			idx.Files[name] = hash
+
+			continue
+		}
+
+		record, err := NewRecord(file, src)
+		if err != nil {
+			return stats, err
+		}
+
+		idx.Files[name], idx.Records[hash] = hash, record
+		stats.Indexed++
+	}
+
+	for name := range idx.Files {
+		if _, err := os.Stat(filepath.FromSlash(name)); errors.Is(err, os.ErrNotExist) {
+			delete(idx.Files, name)
+			stats.Removed++
+		}
+	}
+
+	idx.removeUnused()
+
+	return stats, nil
+}
+
+// removeUnused removes the records no file refers to.
+func (idx *Index) removeUnused() {
+	var used = map[string]bool{}
+	for _, hash := range idx.Files {
+		used[hash] = true
+	}
+
+	for hash := range idx.Records {
+		if !used[hash] {
+			delete(idx.Records, hash)
+		}
+	}
+}
+
+// Hash returns the content hash records are keyed by.
+func Hash(src []byte) string {
+	var sum = sha256.Sum256(src)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// NewRecord parses the given source and records its contents.
+func NewRecord(file string, src []byte) (*Record, error) {
+	var fileSet = token.NewFileSet()
+
+	f, err := parser.ParseFile(fileSet, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var s = newSets()
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n != nil {
+			s.add(n)
+		}
+
+		return true
+	})
+
+	var record = s.record()
+	record.Suppressions = len(asterisk.ParseSuppressions(fileSet, f)) > 0
+
+	return record, nil
+}
+
+// sets collects the distinct contents of a file or pattern.
+type sets struct {
+	kinds, idents, selectors, literals map[string]bool
+	// sels holds the identifiers that are selector names.
+	sels map[*ast.Ident]bool
+}
+
+func newSets() *sets {
+	return &sets{
+		kinds:     map[string]bool{},
+		idents:    map[string]bool{},
+		selectors: map[string]bool{},
+		literals:  map[string]bool{},
+		sels:      map[*ast.Ident]bool{},
+	}
+}
+
+func (s *sets) add(n ast.Node) {
+	s.kinds[kind(n)] = true
+
+	switch e := n.(type) {
+	case *ast.SelectorExpr:
+		s.sels[e.Sel] = true
+	case *ast.Ident:
+		if s.sels[e] {
+			s.selectors[e.Name] = true
+		} else {
+			s.idents[e.Name] = true
+		}
+	case *ast.BasicLit:
+		s.literals[e.Value] = true
+	}
+}
+
+func (s *sets) record() *Record {
+	return &Record{Kinds: sorted(s.kinds), Idents: sorted(s.idents), Selectors: sorted(s.selectors), Literals: sorted(s.literals)}
+}
+
+// kind returns the name of the ast type of the node, e.g. "CallExpr".
+func kind(n ast.Node) string {
+	return reflect.TypeOf(n).Elem().Name()
+}
+
+func sorted(set map[string]bool) []string {
+	var list = make([]string, 0, len(set))
+	for s := range set {
+		list = append(list, s)
+	}
+
+	sort.Strings(list)
+
+	return list
+}
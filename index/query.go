@@ -0,0 +1,108 @@
+package index
+
+import (
+	"go/ast"
+	"os"
+	"sort"
+
+	"github.com/Oppodelldog/asterisk"
+)
+
+// Query holds the records of the contents files need to match each pattern of a query.
+// A file may match if its record contains any of them.
+type Query []*Record
+
+// PatternRecord returns the node kinds, identifiers, selector names and literals every file matching the pattern
+// contains. Variables do not add contents.
+func PatternRecord(p *asterisk.CodePattern) *Record {
+	var s = newSets()
+
+	ast.Inspect(p.Node(), func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+
+		if _, ok := p.Var(n); ok {
+			return false
+		}
+
+		s.add(n)
+
+		return true
+	})
+
+	return s.record()
+}
+
+// RulesQuery returns the Query of the given rules. Rules written in Go may match any file.
+func RulesQuery(rules asterisk.Rules) (Query, error) {
+	var q Query
+
+	for _, r := range rules {
+		if r.Check != nil {
+			q = append(q, &Record{})
+
+			continue
+		}
+
+		p, err := asterisk.ParseCodePattern(r.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		q = append(q, PatternRecord(p))
+	}
+
+	return q, nil
+}
+
+// MayMatch reports whether a file of the given record may match the query.
+func (q Query) MayMatch(r *Record) bool {
+	for _, required := range q {
+		if r.Contains(required) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Contains reports whether the record contains all contents of the other record.
+func (r *Record) Contains(other *Record) bool {
+	return containsAll(r.Kinds, other.Kinds) &&
+		containsAll(r.Idents, other.Idents) &&
+		containsAll(r.Selectors, other.Selectors) &&
+		containsAll(r.Literals, other.Literals)
+}
+
+func containsAll(sorted, values []string) bool {
+	for _, v := range values {
+		if i := sort.SearchStrings(sorted, v); i == len(sorted) || sorted[i] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Candidates returns the given files that may match the query. Records are looked up by the content of the files,
+// files whose content is not indexed are always candidates, so the result is correct even for an outdated Index.
+// Files containing suppression directives are candidates too, so their unused suppressions are reported.
+func (idx *Index) Candidates(files []string, q Query) ([]string, error) {
+	var candidates []string
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if record, ok := idx.Records[Hash(src)]; ok && !record.Suppressions && !q.MayMatch(record) {
+			continue
+		}
+
+		candidates = append(candidates, file)
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,48 @@
+package asterisk
+
+import (
+	"go/ast"
+	"reflect"
+	"sort"
+)
+
+// Kinds returns the given condition declaring the node kinds it can accept, given as example nodes,
+// e.g. new(ast.CallExpr). Walk does not call the first condition of a matcher for nodes of other kinds,
+// so the condition must not match them. All condition constructors of this package declare their kinds.
+func Kinds(c NodeCondition, nodes ...ast.Node) NodeCondition {
+	c.kinds = kindSet(nodes)
+
+	return c
+}
+
+func kindSet(nodes []ast.Node) map[reflect.Type]bool {
+	var kinds = make(map[reflect.Type]bool, len(nodes))
+	for _, n := range nodes {
+		kinds[reflect.TypeOf(n)] = true
+	}
+
+	return kinds
+}
+
+func kindName(n interface{}) string {
+	var t = reflect.TypeOf(n)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// KindsOf returns the node kinds the given condition declares to accept, or nil if it may accept any node.
+func KindsOf(c NodeCondition) []reflect.Type {
+	var kinds []reflect.Type
+	for k := range c.kinds {
+		kinds = append(kinds, k)
+	}
+
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i].String() < kinds[j].String()
+	})
+
+	return kinds
+}
@@ -0,0 +1,112 @@
+package asterisk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"time"
+)
+
+// ErrLimitExceeded is wrapped by all *LimitError.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// Limits of walks and files, as reported by LimitError.
+const (
+	LimitMatches  = "matches"
+	LimitDuration = "duration"
+	LimitFileSize = "file size"
+)
+
+// checkInterval is the number of nodes visited between checks of the context and the time budget of a walk.
+const checkInterval = 1024
+
+// LimitError reports the limit that aborted a walk or skipped a file.
+// The matches processed before the limit was hit are kept.
+type LimitError struct {
+	// Limit is one of LimitMatches, LimitDuration and LimitFileSize.
+	Limit string
+	// Max is the configured maximum.
+	Max interface{}
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %v, max %v", ErrLimitExceeded, e.Limit, e.Max)
+}
+
+// Unwrap returns ErrLimitExceeded.
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// WithContext aborts the walk once the given context is done, the error of the context is returned.
+// The context is checked periodically while walking.
+func WithContext(ctx context.Context) WalkOption {
+	return func(c *walkConfig) {
+		c.ctx = ctx
+	}
+}
+
+// MaxMatches stops the walk at the first match exceeding n processed matches and returns a *LimitError.
+func MaxMatches(n int) WalkOption {
+	return func(c *walkConfig) {
+		c.maxMatches = n
+	}
+}
+
+// Timeout aborts a walk taking longer than d and returns a *LimitError.
+// Engines apply it to each file, so it is a time budget per file.
+func Timeout(d time.Duration) WalkOption {
+	return func(c *walkConfig) {
+		c.timeout = d
+	}
+}
+
+// walkLimits tracks the context and the limits of a single walk.
+type walkLimits struct {
+	ctx      context.Context
+	deadline time.Time
+	timeout  time.Duration
+	visited  int
+	err      error
+}
+
+func newWalkLimits(cfg walkConfig) *walkLimits {
+	var l = &walkLimits{ctx: cfg.ctx, timeout: cfg.timeout}
+	if l.timeout > 0 {
+		l.deadline = time.Now().Add(l.timeout)
+	}
+
+	return l
+}
+
+// exceeded reports whether the context is done or the time budget is used up, checking them every checkInterval nodes.
+func (l *walkLimits) exceeded() bool {
+	if l.visited++; l.visited%checkInterval != 1 {
+		return false
+	}
+
+	switch {
+	case l.ctx != nil && l.ctx.Err() != nil:
+		l.err = l.ctx.Err()
+	case l.timeout > 0 && time.Now().After(l.deadline):
+		l.err = &LimitError{Limit: LimitDuration, Max: l.timeout}
+	}
+
+	return l.err != nil
+}
+
+// limitMatches processes up to max matches, the next match stops the walk.
+func (l *walkLimits) limitMatches(max int, process processFunc) processFunc {
+	var matches int
+
+	return func(pm *Matcher, first ast.Node, s NodeSelections) error {
+		if matches++; matches > max {
+			l.err = &LimitError{Limit: LimitMatches, Max: max}
+
+			return Stop
+		}
+
+		return process(pm, first, s)
+	}
+}
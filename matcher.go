@@ -1,39 +1,300 @@
 package asterisk
 
-import "go/ast"
+import (
+	"go/ast"
+	"reflect"
+)
 
-// New returns a new instance of a Matcher.
-func New(conditions []NodeCondition, processMatch func()) *Matcher {
-	return &Matcher{conditions: conditions, processMatch: processMatch}
+// Pattern is the compiled, immutable part of a Matcher: its name, conditions and match processing.
+// A Pattern holds no traversal state, the nodes selected during a match are kept by the walk and passed
+// to processMatch, so it can be shared by concurrent walks, as long as processMatch is safe for concurrent use.
+// Conditions created by NodeSelections.Select write to their selections instead, so a Pattern using them
+// must be created per walk, e.g. by the factory of an Engine.
+type Pattern struct {
+	name         string
+	conditions   []NodeCondition
+	processMatch func(s NodeSelections) error
+	kinds        map[reflect.Type]bool
+}
+
+// NewPattern compiles the given conditions into a Pattern.
+// Once all conditions have matched a sequence of nodes, processMatch is called with the nodes
+// the conditions selected during the match, e.g. by Select. A Pattern without conditions never matches.
+func NewPattern(name string, conditions []NodeCondition, processMatch func(s NodeSelections) error) *Pattern {
+	var p = &Pattern{
+		name:         name,
+		conditions:   append([]NodeCondition(nil), conditions...),
+		processMatch: processMatch,
+		kinds:        map[reflect.Type]bool{},
+	}
+
+	if len(conditions) > 0 {
+		p.kinds = conditions[0].kinds
+	}
+
+	return p
+}
+
+// Name returns the name of the rule the Pattern implements.
+func (p *Pattern) Name() string {
+	return p.name
+}
+
+// Matcher returns a new Matcher of the Pattern.
+func (p *Pattern) Matcher() *Matcher {
+	return &Matcher{pattern: p}
+}
+
+// accepts reports whether the first condition of the pattern can accept nodes of the given kind.
+func (p *Pattern) accepts(kind reflect.Type) bool {
+	return p.kinds == nil || p.kinds[kind]
+}
+
+// matchState is the traversal state of a pattern: the index of the next condition,
+// the first node of the current match and the nodes selected during the match.
+type matchState struct {
+	idx        int
+	first      ast.Node
+	selections NodeSelections
+}
+
+// selected hands over the selections of a completed match, the next match selects into new ones.
+func (s *matchState) selected() NodeSelections {
+	var selections = s.selections
+
+	s.selections = nil
+
+	return selections
+}
+
+func (p *Pattern) match(ev *evaluation, n ast.Node, s *matchState) bool {
+	if len(p.conditions) == 0 {
+		return false
+	}
+
+	if s.idx == 0 {
+		clear(s.selections)
+	}
+
+	if ev.state = s; ev.profile != nil {
+		ev.profile.enter(p.name)
+	}
+
+	if !p.conditions[s.idx].match(ev, n) {
+		s.idx = 0
+
+		return false
+	}
+
+	if s.idx == 0 {
+		s.first = n
+	}
+
+	s.idx++
+	if s.idx < len(p.conditions) {
+		return false
+	}
+
+	s.idx = 0
+
+	return true
+}
+
+// New returns a new instance of a Matcher, processMatch reads the nodes selected by its conditions
+// from the NodeSelections they were selected in.
+func New(conditions []NodeCondition, processMatch func() error) *Matcher {
+	return NewPattern("", conditions, func(NodeSelections) error {
+		return processMatch()
+	}).Matcher()
 }
 
 // Matcher helps to find ast portions of interest while walking through the tree.
+// Walk keeps its own state for each Matcher, the state of the Matcher is only used by Match.
 type Matcher struct {
-	conditions   []NodeCondition
-	idx          int
-	processMatch func()
-}
-
-// Walk will sequentially called detect node chains by the configured conditions.
-// Once all conditions have matched, the ProcessMatch will be called.
-func (pm *Matcher) Match(n ast.Node) {
-	if pm.conditions[pm.idx](n) {
-		pm.idx++
-		if pm.idx >= len(pm.conditions) {
-			pm.idx = 0
-			pm.processMatch()
-		}
-	} else {
-		pm.idx = 0
+	pattern *Pattern
+	state   matchState
+	ev      evaluation
+}
+
+// Named sets the name of the rule the Matcher implements, it is used to identify the rule in errors.
+func (pm *Matcher) Named(name string) *Matcher {
+	var p = *pm.pattern
+
+	p.name = name
+	pm.pattern = &p
+
+	return pm
+}
+
+// Name returns the name of the rule the Matcher implements.
+func (pm *Matcher) Name() string {
+	return pm.pattern.name
+}
+
+// Pattern returns the immutable Pattern of the Matcher.
+func (pm *Matcher) Pattern() *Pattern {
+	return pm.pattern
+}
+
+// Match will sequentially detect node chains by the configured conditions.
+// Once all conditions have matched, the processMatch will be called.
+// An error returned by processMatch is returned as *MatchError.
+func (pm *Matcher) Match(n ast.Node) error {
+	if err := pm.match(&pm.ev, n, &pm.state, process); err != nil {
+		return err
 	}
+
+	return nil
+}
+
+// processFunc invokes the processMatch function of a matcher that completed a match starting at first,
+// s holds the nodes selected during the match.
+type processFunc func(pm *Matcher, first ast.Node, s NodeSelections) error
+
+func process(pm *Matcher, _ ast.Node, s NodeSelections) error {
+	return pm.pattern.processMatch(s)
+}
+
+func (pm *Matcher) match(ev *evaluation, n ast.Node, s *matchState, process processFunc) *MatchError {
+	if !pm.pattern.match(ev, n, s) {
+		return nil
+	}
+
+	return pm.process(s.first, s.selected(), process)
+}
+
+func (pm *Matcher) process(first ast.Node, s NodeSelections, process processFunc) *MatchError {
+	if err := process(pm, first, s); err != nil {
+		return &MatchError{Rule: pm.pattern.name, Node: first, Err: err}
+	}
+
+	return nil
 }
 
 // PatternMatchers holds multiple matchers.
 type PatternMatchers []*Matcher
 
-// Walk matches all matchers against the given node.
-func (m PatternMatchers) Match(n ast.Node) {
-	for _, matcher := range m {
-		matcher.Match(n)
+// Match matches all matchers against the given node.
+// Errors of all failing matchers are returned as MatchErrors.
+func (m PatternMatchers) Match(n ast.Node) error {
+	var (
+		errs MatchErrors
+		ev   = newEvaluation()
+		kind = reflect.TypeOf(n)
+	)
+
+	for _, pm := range m {
+		if pm.state.idx == 0 && !pm.pattern.accepts(kind) {
+			continue
+		}
+
+		if err := pm.match(ev, n, &pm.state, process); err != nil {
+			errs = append(errs, err)
+		}
 	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// dispatcher holds the state of the matchers during a walk and passes each node only to the matchers
+// whose current condition can accept its kind. Matchers in the middle of a match are always visited,
+// since a mismatch resets them. The state is reset at the start of each file.
+type dispatcher struct {
+	ev      *evaluation
+	entries []dispatchEntry
+	byKind  map[reflect.Type][]int
+	active  int
+}
+
+type dispatchEntry struct {
+	pm      *Matcher
+	pattern *Pattern
+	state   matchState
+}
+
+func newDispatcher(pms PatternMatchers, ev *evaluation) *dispatcher {
+	var d = &dispatcher{ev: ev, entries: make([]dispatchEntry, len(pms)), byKind: map[reflect.Type][]int{}}
+	for i, pm := range pms {
+		d.entries[i] = dispatchEntry{pm: pm, pattern: pm.pattern}
+	}
+
+	return d
+}
+
+// match passes the node to the matchers and the errors of failing matches to handle.
+// If handle returns false, the node is not passed to the remaining matchers.
+func (d *dispatcher) match(n ast.Node, process processFunc, handle func(*MatchError) bool) {
+	if _, ok := n.(*ast.File); ok {
+		d.reset()
+	}
+
+	var kind = reflect.TypeOf(n)
+
+	if d.active > 0 {
+		for i := range d.entries {
+			if err := d.visit(&d.entries[i], n, kind, process); err != nil && !handle(err) {
+				return
+			}
+		}
+
+		return
+	}
+
+	for _, i := range d.candidates(kind) {
+		if err := d.visit(&d.entries[i], n, kind, process); err != nil && !handle(err) {
+			return
+		}
+	}
+}
+
+// visit matches the node against the matcher of the entry and keeps track of the number of active matchers.
+func (d *dispatcher) visit(e *dispatchEntry, n ast.Node, kind reflect.Type, process processFunc) *MatchError {
+	var wasActive = e.state.idx > 0
+
+	if !wasActive && !e.pattern.accepts(kind) {
+		return nil
+	}
+
+	var matched = e.pattern.match(d.ev, n, &e.state)
+
+	switch isActive := e.state.idx > 0; {
+	case isActive && !wasActive:
+		d.active++
+	case !isActive && wasActive:
+		d.active--
+	}
+
+	if !matched {
+		return nil
+	}
+
+	return e.pm.process(e.state.first, e.state.selected(), process)
+}
+
+// candidates returns the indexes of the matchers whose first condition can accept the given kind.
+func (d *dispatcher) candidates(kind reflect.Type) []int {
+	indexes, ok := d.byKind[kind]
+	if !ok {
+		for i, e := range d.entries {
+			if e.pattern.accepts(kind) {
+				indexes = append(indexes, i)
+			}
+		}
+
+		d.byKind[kind] = indexes
+	}
+
+	return indexes
+}
+
+func (d *dispatcher) reset() {
+	for i := range d.entries {
+		d.entries[i].state = matchState{}
+	}
+
+	d.active = 0
 }
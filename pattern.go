@@ -0,0 +1,236 @@
+package asterisk
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidPattern is returned for code patterns that cannot be parsed.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+const (
+	varPrefix = "__asterisk_"
+	wildcard  = "_"
+)
+
+var (
+	varRegex         = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
+	commentGroupType = reflect.TypeOf((*ast.CommentGroup)(nil))
+)
+
+// CodePattern is a pattern written as Go code, e.g. "logrus.SetLevel($level)".
+// Variables starting with $ match any node and capture it, $_ matches any node without capturing it.
+// A variable used multiple times has to match equal nodes.
+// A variable standing alone as statement matches any statement.
+type CodePattern struct {
+	src  string
+	node ast.Node
+	vars []string
+}
+
+// ParseCodePattern parses the given Go expression or statement as CodePattern.
+func ParseCodePattern(src string) (*CodePattern, error) {
+	node, err := parseCode(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var vars []string
+
+	for _, m := range varRegex.FindAllStringSubmatch(src, -1) {
+		if m[1] != wildcard && !contains(vars, m[1]) {
+			vars = append(vars, m[1])
+		}
+	}
+
+	sort.Strings(vars)
+
+	return &CodePattern{src: src, node: node, vars: vars}, nil
+}
+
+// String returns the source of the pattern.
+func (p *CodePattern) String() string {
+	return p.src
+}
+
+// Node returns the parsed pattern, variables are identifiers carrying an internal prefix.
+func (p *CodePattern) Node() ast.Node {
+	return p.node
+}
+
+// Var returns the name of the variable if the given node of the pattern is a variable.
+func (p *CodePattern) Var(n ast.Node) (string, bool) {
+	return patternVar(n)
+}
+
+// Vars returns the names of the variables the pattern captures.
+func (p *CodePattern) Vars() []string {
+	return p.vars
+}
+
+// Condition returns a NodeCondition that matches nodes against the pattern.
+// On a match, the captured nodes are selected for the current match like Select does, using the variable names
+// as keys. The condition declares the kind of the root node of the pattern, unless the root is a variable.
+func (p *CodePattern) Condition() NodeCondition {
+	var c = NodeCondition{
+		eval: func(ev *evaluation, n ast.Node) bool {
+			var captures = map[string]ast.Node{}
+
+			if !matchPattern(reflect.ValueOf(p.node), reflect.ValueOf(n), captures) {
+				return false
+			}
+
+			for name, node := range captures {
+				ev.selectNodes(name, node)
+			}
+
+			return true
+		},
+		label: "Pattern(" + p.src + ")",
+	}
+
+	if _, ok := patternVar(p.node); !ok {
+		c.kinds = kindSet([]ast.Node{p.node})
+	}
+
+	return c
+}
+
+// parseCode parses the given code as expression, or as single statement if it is no expression.
+// Variables are replaced by identifiers, so the code is valid Go.
+func parseCode(src string) (ast.Node, error) {
+	var code = varRegex.ReplaceAllString(src, varPrefix+"$1")
+
+	if expr, err := parser.ParseExpr(code); err == nil {
+		return expr, nil
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p; func _() {\n"+code+"\n}", 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidPattern, src, err)
+	}
+
+	var body = f.Decls[0].(*ast.FuncDecl).Body.List
+	if len(body) != 1 {
+		return nil, fmt.Errorf("%w %q: expected a single expression or statement", ErrInvalidPattern, src)
+	}
+
+	return body[0], nil
+}
+
+// patternVar returns the variable name if the given pattern node is a variable.
+func patternVar(p ast.Node) (string, bool) {
+	switch e := p.(type) {
+	case *ast.Ident:
+		if strings.HasPrefix(e.Name, varPrefix) {
+			return strings.TrimPrefix(e.Name, varPrefix), true
+		}
+	case *ast.ExprStmt:
+		return patternVar(e.X)
+	}
+
+	return "", false
+}
+
+func matchPattern(p, n reflect.Value, captures map[string]ast.Node) bool {
+	if p.Kind() == reflect.Interface {
+		if p.IsNil() {
+			return n.IsNil()
+		}
+
+		return matchPattern(p.Elem(), n, captures)
+	}
+
+	if n.Kind() == reflect.Interface {
+		if n.IsNil() {
+			return false
+		}
+
+		n = n.Elem()
+	}
+
+	switch p.Kind() {
+	case reflect.Ptr:
+		if node, ok := p.Interface().(ast.Node); ok && !p.IsNil() {
+			if name, ok := patternVar(node); ok {
+				return capture(name, n, captures)
+			}
+		}
+
+		if p.Type() != n.Type() {
+			return false
+		}
+
+		if p.IsNil() || n.IsNil() {
+			return p.IsNil() == n.IsNil()
+		}
+
+		return matchPattern(p.Elem(), n.Elem(), captures)
+	case reflect.Slice:
+		if p.Len() != n.Len() {
+			return false
+		}
+
+		for i := 0; i < p.Len(); i++ {
+			if !matchPattern(p.Index(i), n.Index(i), captures) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Struct:
+		for i := 0; i < p.NumField(); i++ {
+			if ignoredField(p.Field(i).Type()) {
+				continue
+			}
+
+			if !matchPattern(p.Field(i), n.Field(i), captures) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return p.Interface() == n.Interface()
+	}
+}
+
+func capture(name string, n reflect.Value, captures map[string]ast.Node) bool {
+	node, ok := n.Interface().(ast.Node)
+	if !ok || n.IsNil() {
+		return false
+	}
+
+	if name == wildcard {
+		return true
+	}
+
+	if captured, ok := captures[name]; ok {
+		return matchPattern(reflect.ValueOf(captured), n, map[string]ast.Node{})
+	}
+
+	captures[name] = node
+
+	return true
+}
+
+func ignoredField(t reflect.Type) bool {
+	return t == posType || t == objectType || t == scopeType || t == commentGroupType
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
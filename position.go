@@ -0,0 +1,233 @@
+package asterisk
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Positions provides position-aware conditions for the statements of a file, e.g. to check blank lines.
+// Sibling based conditions consider the statements of blocks and case clauses.
+type Positions struct {
+	fileSet  *token.FileSet
+	file     *token.File
+	comments map[int]bool
+	first    map[int]token.Pos
+	siblings map[ast.Node]sibling
+}
+
+// sibling describes the place of a statement in its statement list.
+// before is the end of the previous statement or the opening token of the list,
+// after the start of the next statement or the closing token of the list.
+type sibling struct {
+	parent        ast.Node
+	prev          ast.Node
+	index         int
+	last          bool
+	before, after token.Pos
+}
+
+// NewPositions indexes the statements and comments of the given file, which has to be parsed with comments.
+func NewPositions(fileSet *token.FileSet, file *ast.File) *Positions {
+	var p = &Positions{
+		fileSet:  fileSet,
+		file:     fileSet.File(file.Pos()),
+		comments: map[int]bool{},
+		first:    map[int]token.Pos{},
+		siblings: map[ast.Node]sibling{},
+	}
+
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			for line := p.line(c.Pos()); line <= p.line(c.End()); line++ {
+				p.comments[line] = true
+			}
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case nil, *ast.CommentGroup:
+			return false
+		case *ast.BlockStmt:
+			p.addList(e, e.List, e.Lbrace+1, e.Rbrace)
+
+			for i, stmt := range e.List {
+				var after = e.Rbrace
+				if i < len(e.List)-1 {
+					after = e.List[i+1].Pos()
+				}
+
+				switch clause := stmt.(type) {
+				case *ast.CaseClause:
+					p.addList(clause, clause.Body, clause.Colon+1, after)
+				case *ast.CommClause:
+					p.addList(clause, clause.Body, clause.Colon+1, after)
+				}
+			}
+		}
+
+		p.addCode(n.Pos())
+		p.addCode(n.End() - 1)
+
+		return true
+	})
+
+	return p
+}
+
+func (p *Positions) addList(parent ast.Node, stmts []ast.Stmt, before, after token.Pos) {
+	for i, stmt := range stmts {
+		var s = sibling{parent: parent, index: i, last: i == len(stmts)-1, before: before, after: after}
+
+		if i > 0 {
+			s.prev = stmts[i-1]
+			s.before = stmts[i-1].End()
+		}
+
+		if !s.last {
+			s.after = stmts[i+1].Pos()
+		}
+
+		p.siblings[stmt] = s
+	}
+}
+
+// addCode remembers the first code position of each line.
+func (p *Positions) addCode(pos token.Pos) {
+	if !pos.IsValid() {
+		return
+	}
+
+	var line = p.line(pos)
+	if first, ok := p.first[line]; !ok || pos < first {
+		p.first[line] = pos
+	}
+}
+
+func (p *Positions) line(pos token.Pos) int {
+	return p.fileSet.Position(pos).Line
+}
+
+// blankLines returns the blank lines between the lines of from and to, comment lines are not blank.
+func (p *Positions) blankLines(from, to token.Pos) []int {
+	var lines []int
+
+	for line := p.line(from) + 1; line < p.line(to); line++ {
+		if !p.comments[line] {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// BlankLinesBefore matches statements whose number of blank lines before them, up to the previous statement
+// or the opening token of their statement list, matches the given condition.
+func (p *Positions) BlankLinesBefore(c IntCondition) NodeCondition {
+	return Func(func(n ast.Node) bool {
+		s, ok := p.siblings[n]
+
+		return ok && c(len(p.blankLines(s.before-1, n.Pos())))
+	})
+}
+
+// BlankLinesAfter matches statements whose number of blank lines after them, up to the next statement
+// or the closing token of their statement list, matches the given condition.
+func (p *Positions) BlankLinesAfter(c IntCondition) NodeCondition {
+	return Func(func(n ast.Node) bool {
+		s, ok := p.siblings[n]
+
+		return ok && c(len(p.blankLines(n.End()-1, s.after)))
+	})
+}
+
+// OnSameLine matches statements that start on the line their previous statement ends on,
+// if the previous statement matches other.
+func (p *Positions) OnSameLine(other NodeCondition) NodeCondition {
+	return NodeCondition{eval: func(ev *evaluation, n ast.Node) bool {
+		s, ok := p.siblings[n]
+
+		return ok && s.prev != nil && p.line(s.prev.End()-1) == p.line(n.Pos()) && other.match(ev, s.prev)
+	}}
+}
+
+// StartsLine matches nodes that are not preceded by other code on their line.
+func (p *Positions) StartsLine() NodeCondition {
+	return Func(func(n ast.Node) bool {
+		return p.first[p.line(n.Pos())] == n.Pos()
+	})
+}
+
+// Index matches statements whose index in their statement list matches the given condition.
+func (p *Positions) Index(c IntCondition) NodeCondition {
+	return Func(func(n ast.Node) bool {
+		s, ok := p.siblings[n]
+
+		return ok && c(s.index)
+	})
+}
+
+// Last matches the last statement of a statement list.
+func (p *Positions) Last() NodeCondition {
+	return Func(func(n ast.Node) bool {
+		return p.siblings[n].last
+	})
+}
+
+// Parent matches statements whose block or case clause matches the given condition.
+func (p *Positions) Parent(c NodeCondition) NodeCondition {
+	return NodeCondition{eval: func(ev *evaluation, n ast.Node) bool {
+		s, ok := p.siblings[n]
+
+		return ok && c.match(ev, s.parent)
+	}}
+}
+
+// InsertBlankLineBefore returns the edit inserting a blank line before the given statement
+// and the comments directly above it.
+func (p *Positions) InsertBlankLineBefore(n ast.Node) []TextEdit {
+	var line = p.line(n.Pos())
+
+	if s, ok := p.siblings[n]; ok {
+		for line-1 > p.line(s.before-1) && p.comments[line-1] {
+			line--
+		}
+	}
+
+	var pos = p.fileSet.Position(p.file.LineStart(line))
+
+	return []TextEdit{{Pos: pos, End: pos, NewText: "\n"}}
+}
+
+// DeleteBlankLinesBefore returns the edits deleting the blank lines before the given statement.
+func (p *Positions) DeleteBlankLinesBefore(n ast.Node) []TextEdit {
+	s, ok := p.siblings[n]
+	if !ok {
+		return nil
+	}
+
+	return p.deleteLines(p.blankLines(s.before-1, n.Pos()))
+}
+
+// DeleteBlankLinesAfter returns the edits deleting the blank lines after the given statement.
+func (p *Positions) DeleteBlankLinesAfter(n ast.Node) []TextEdit {
+	s, ok := p.siblings[n]
+	if !ok {
+		return nil
+	}
+
+	return p.deleteLines(p.blankLines(n.End()-1, s.after))
+}
+
+func (p *Positions) deleteLines(lines []int) []TextEdit {
+	var edits []TextEdit
+
+	for _, line := range lines {
+		edits = append(edits, TextEdit{
+			Pos: p.fileSet.Position(p.file.LineStart(line)),
+			End: p.fileSet.Position(p.file.LineStart(line + 1)),
+		})
+	}
+
+	return edits
+}
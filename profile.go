@@ -0,0 +1,260 @@
+package asterisk
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Profiler records how often conditions are called, how often they match and the time spent in them.
+// Stats are grouped by the rule evaluating a condition and by the path of nested conditions leading to it,
+// e.g. "CallExpr/SelectorExpr/Ident(logrus)". A Profiler is passed to walks by Profile, it is safe for concurrent walks.
+type Profiler struct {
+	mu    sync.Mutex
+	rules map[string]*profileFrame
+}
+
+// profileFrame holds the stats of a condition path, the root frames represent rules.
+type profileFrame struct {
+	children map[string]*profileFrame
+	calls    int
+	matches  int
+	time     time.Duration
+}
+
+// ConditionStats are the stats of a condition path of a rule.
+type ConditionStats struct {
+	Rule string
+	// Path holds the labels of the nested conditions, separated by "/".
+	Path    string
+	Calls   int
+	Matches int
+	// Time is the cumulative time spent in the condition, including its nested conditions.
+	Time time.Duration
+}
+
+// Ratio returns the share of calls the condition matched.
+func (s ConditionStats) Ratio() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+
+	return float64(s.Matches) / float64(s.Calls)
+}
+
+// NewProfiler returns an empty Profiler.
+func NewProfiler() *Profiler {
+	return &Profiler{rules: map[string]*profileFrame{}}
+}
+
+// Profile records the labeled conditions evaluated by the walk in the given Profiler, the conditions created by
+// this package are labeled. Walks without Profile only check whether a walk is profiled per condition.
+func Profile(p *Profiler) WalkOption {
+	return func(c *walkConfig) {
+		c.profiler = p
+	}
+}
+
+// sharedRule groups the evaluations shared by the conditions of several rules.
+const sharedRule = "(shared)"
+
+// walkProfile records the conditions of a single walk, it is merged into its Profiler once the walk ended.
+type walkProfile struct {
+	rules   map[string]*profileFrame
+	current *profileFrame
+	// shared is the total time of shared evaluations, it is not charged to the conditions evaluating them.
+	shared time.Duration
+}
+
+func newWalkProfile() *walkProfile {
+	return &walkProfile{rules: map[string]*profileFrame{}}
+}
+
+// enter records the conditions evaluated next into the frame of the given rule.
+func (w *walkProfile) enter(rule string) {
+	w.current = w.rule(rule)
+}
+
+func (w *walkProfile) rule(name string) *profileFrame {
+	if name == "" {
+		name = "(unnamed)"
+	}
+
+	var frame, ok = w.rules[name]
+	if !ok {
+		frame = &profileFrame{}
+		w.rules[name] = frame
+	}
+
+	return frame
+}
+
+// call records the evaluation of a labeled condition as child of the condition evaluating it.
+func (w *walkProfile) call(label string, eval func() bool) bool {
+	var (
+		parent = w.current
+		frame  = parent.child(label)
+		start  = time.Now()
+		shared = w.shared
+	)
+
+	w.current = frame
+	var matched = eval()
+	w.current = parent
+
+	frame.calls++
+	frame.time += time.Since(start) - (w.shared - shared)
+
+	if matched {
+		frame.matches++
+	}
+
+	return matched
+}
+
+// evalShared records an evaluation shared by the conditions of several rules, like the match of a DecisionTree,
+// as condition of the pseudo rule "(shared)". Its time is not charged to the condition evaluating it first.
+func (w *walkProfile) evalShared(label string, eval func() bool) {
+	var (
+		frame   = w.rule(sharedRule).child(label)
+		start   = time.Now()
+		matched = eval()
+		elapsed = time.Since(start)
+	)
+
+	frame.calls++
+	frame.time += elapsed
+	w.shared += elapsed
+
+	if matched {
+		frame.matches++
+	}
+}
+
+// merge adds the stats recorded by the given walk.
+func (p *Profiler) merge(w *walkProfile) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for rule, frame := range w.rules {
+		if _, ok := p.rules[rule]; !ok {
+			p.rules[rule] = &profileFrame{}
+		}
+
+		p.rules[rule].add(frame)
+	}
+}
+
+func (f *profileFrame) add(other *profileFrame) {
+	f.calls += other.calls
+	f.matches += other.matches
+	f.time += other.time
+
+	for label, child := range other.children {
+		f.child(label).add(child)
+	}
+}
+
+func (f *profileFrame) child(label string) *profileFrame {
+	if f.children == nil {
+		f.children = map[string]*profileFrame{}
+	}
+
+	var child, ok = f.children[label]
+	if !ok {
+		child = &profileFrame{}
+		f.children[label] = child
+	}
+
+	return child
+}
+
+// Stats returns the stats of all condition paths, ordered by rule and path.
+func (p *Profiler) Stats() []ConditionStats {
+	var stats []ConditionStats
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for rule, frame := range p.rules {
+		frame.collect(rule, "", &stats)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Rule != stats[j].Rule {
+			return stats[i].Rule < stats[j].Rule
+		}
+
+		return stats[i].Path < stats[j].Path
+	})
+
+	return stats
+}
+
+func (f *profileFrame) collect(rule, path string, stats *[]ConditionStats) {
+	for label, child := range f.children {
+		var childPath = label
+		if path != "" {
+			childPath = path + "/" + label
+		}
+
+		*stats = append(*stats, ConditionStats{
+			Rule:    rule,
+			Path:    childPath,
+			Calls:   child.calls,
+			Matches: child.matches,
+			Time:    child.time,
+		})
+
+		child.collect(rule, childPath, stats)
+	}
+}
+
+// WriteReport writes the stats grouped by rule, rules are ordered by the time spent in their conditions.
+// Conditions that never matched are marked, they may indicate rules that never fire.
+func (p *Profiler) WriteReport(w io.Writer) error {
+	var (
+		stats  = p.Stats()
+		byRule = map[string][]ConditionStats{}
+		totals = map[string]time.Duration{}
+		rules  []string
+	)
+
+	for _, s := range stats {
+		if _, ok := byRule[s.Rule]; !ok {
+			rules = append(rules, s.Rule)
+		}
+
+		byRule[s.Rule] = append(byRule[s.Rule], s)
+
+		if !strings.Contains(s.Path, "/") {
+			totals[s.Rule] += s.Time
+		}
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return totals[rules[i]] > totals[rules[j]]
+	})
+
+	var tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, rule := range rules {
+		fmt.Fprintf(tw, "%v\t%v\n", rule, totals[rule])
+		fmt.Fprintln(tw, "  PATH\tCALLS\tMATCHES\tRATIO\tTIME\t")
+
+		for _, s := range byRule[rule] {
+			var never string
+			if s.Matches == 0 {
+				never = "never matched"
+			}
+
+			fmt.Fprintf(tw, "  %v\t%v\t%v\t%.1f%%\t%v\t%v\n", s.Path, s.Calls, s.Matches, 100*s.Ratio(), s.Time, never)
+		}
+	}
+
+	return tw.Flush()
+}
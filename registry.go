@@ -0,0 +1,99 @@
+package asterisk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnknownRule is returned when selecting rules by an id, category or tag no rule has.
+var ErrUnknownRule = errors.New("unknown rule")
+
+// Registry holds rules by id, so they can be listed and selected by id, category or tag.
+type Registry struct {
+	rules map[string]*Rule
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{rules: map[string]*Rule{}}
+}
+
+// Register compiles and adds the given rules, ids have to be unique.
+func (r *Registry) Register(rules ...*Rule) error {
+	for _, rule := range rules {
+		if _, ok := r.rules[rule.ID]; ok {
+			return fmt.Errorf("%w: %v", ErrDuplicateRule, rule.ID)
+		}
+
+		if err := rule.Compile(); err != nil {
+			return err
+		}
+
+		r.rules[rule.ID] = rule
+	}
+
+	return nil
+}
+
+// Rule returns the rule with the given id.
+func (r *Registry) Rule(id string) (*Rule, bool) {
+	rule, ok := r.rules[id]
+
+	return rule, ok
+}
+
+// Rules returns all registered rules ordered by id.
+func (r *Registry) Rules() Rules {
+	var rules = make(Rules, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+
+	return rules
+}
+
+// Select returns the rules matching any of the enable selectors, or all rules if there are none,
+// without the rules matching any of the disable selectors. A selector is a rule id, category or tag.
+func (r *Registry) Select(enable, disable []string) (Rules, error) {
+	for _, selector := range append(append([]string{}, enable...), disable...) {
+		if !r.known(selector) {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownRule, selector)
+		}
+	}
+
+	var rules Rules
+
+	for _, rule := range r.Rules() {
+		if (len(enable) == 0 || rule.selected(enable)) && !rule.selected(disable) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+func (r *Registry) known(selector string) bool {
+	for _, rule := range r.rules {
+		if rule.selected([]string{selector}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selected reports whether the id, category or a tag of the rule is one of the given selectors.
+func (r *Rule) selected(selectors []string) bool {
+	for _, selector := range selectors {
+		if selector == r.ID || selector == r.Category || contains(r.Tags, selector) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+const checkstyleVersion = "4.3"
+
+// NewCheckstyle returns a Reporter that writes a Checkstyle XML report on Close.
+func NewCheckstyle(w io.Writer) Reporter {
+	return &checkstyleReporter{w: w}
+}
+
+type checkstyleReporter struct {
+	buffer
+	w io.Writer
+}
+
+type (
+	checkstyle struct {
+		XMLName xml.Name         `xml:"checkstyle"`
+		Version string           `xml:"version,attr"`
+		Files   []checkstyleFile `xml:"file"`
+	}
+	checkstyleFile struct {
+		Name   string            `xml:"name,attr"`
+		Errors []checkstyleError `xml:"error"`
+	}
+	// checkstyleError extends the Checkstyle format by the end of the finding and the edits fixing it.
+	checkstyleError struct {
+		Line      int            `xml:"line,attr"`
+		Column    int            `xml:"column,attr"`
+		EndLine   int            `xml:"endLine,attr,omitempty"`
+		EndColumn int            `xml:"endColumn,attr,omitempty"`
+		Severity  string         `xml:"severity,attr"`
+		Message   string         `xml:"message,attr"`
+		Source    string         `xml:"source,attr"`
+		Fix       *checkstyleFix `xml:"fix,omitempty"`
+	}
+	checkstyleFix struct {
+		Edits []checkstyleEdit `xml:"edit"`
+	}
+	checkstyleEdit struct {
+		Line      int    `xml:"line,attr"`
+		Column    int    `xml:"column,attr"`
+		EndLine   int    `xml:"endLine,attr"`
+		EndColumn int    `xml:"endColumn,attr"`
+		NewText   string `xml:",chardata"`
+	}
+)
+
+func (r *checkstyleReporter) Close() error {
+	var (
+		report        = checkstyle{Version: checkstyleVersion}
+		files, byFile = r.byFile()
+	)
+
+	for _, name := range files {
+		var file = checkstyleFile{Name: name}
+
+		for _, d := range byFile[name] {
+			var e = checkstyleError{
+				Line:      d.Pos.Line,
+				Column:    d.Pos.Column,
+				EndLine:   d.End.Line,
+				EndColumn: d.End.Column,
+				Severity:  d.Severity.String(),
+				Message:   d.Message,
+				Source:    d.Rule,
+			}
+
+			if len(d.Edits) > 0 {
+				e.Fix = &checkstyleFix{}
+			}
+
+			for _, edit := range d.Edits {
+				e.Fix.Edits = append(e.Fix.Edits, checkstyleEdit{
+					Line:      edit.Pos.Line,
+					Column:    edit.Pos.Column,
+					EndLine:   edit.End.Line,
+					EndColumn: edit.End.Column,
+					NewText:   edit.NewText,
+				})
+			}
+
+			file.Errors = append(file.Errors, e)
+		}
+
+		report.Files = append(report.Files, file)
+	}
+
+	return writeXML(r.w, report)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	var enc = xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
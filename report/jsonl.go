@@ -0,0 +1,68 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/Oppodelldog/asterisk"
+)
+
+// NewJSONLines returns a Reporter that writes one JSON object per diagnostic and line.
+func NewJSONLines(w io.Writer) Reporter {
+	return &jsonLinesReporter{enc: json.NewEncoder(w)}
+}
+
+type jsonLinesReporter struct {
+	enc *json.Encoder
+}
+
+type jsonDiagnostic struct {
+	Rule        string     `json:"rule"`
+	Severity    string     `json:"severity"`
+	Message     string     `json:"message"`
+	File        string     `json:"file"`
+	Line        int        `json:"line"`
+	Column      int        `json:"column"`
+	EndLine     int        `json:"endLine"`
+	EndColumn   int        `json:"endColumn"`
+	Edits       []jsonEdit `json:"edits,omitempty"`
+	Fingerprint string     `json:"fingerprint,omitempty"`
+}
+
+type jsonEdit struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	NewText   string `json:"newText"`
+}
+
+func (r *jsonLinesReporter) Report(d asterisk.Diagnostic) error {
+	var jd = jsonDiagnostic{
+		Rule:        d.Rule,
+		Severity:    d.Severity.String(),
+		Message:     d.Message,
+		File:        d.Pos.Filename,
+		Line:        d.Pos.Line,
+		Column:      d.Pos.Column,
+		EndLine:     d.End.Line,
+		EndColumn:   d.End.Column,
+		Fingerprint: d.Fingerprint,
+	}
+
+	for _, e := range d.Edits {
+		jd.Edits = append(jd.Edits, jsonEdit{
+			Line:      e.Pos.Line,
+			Column:    e.Pos.Column,
+			EndLine:   e.End.Line,
+			EndColumn: e.End.Column,
+			NewText:   e.NewText,
+		})
+	}
+
+	return r.enc.Encode(jd)
+}
+
+func (r *jsonLinesReporter) Close() error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Oppodelldog/asterisk"
+)
+
+// NewJUnit returns a Reporter that writes a JUnit XML report on Close.
+// Each file is a test suite and each diagnostic a failing test case.
+func NewJUnit(w io.Writer) Reporter {
+	return &junitReporter{w: w}
+}
+
+type junitReporter struct {
+	buffer
+	w io.Writer
+}
+
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+	}
+	junitTestSuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Cases    []junitTestCase `xml:"testcase"`
+	}
+	junitTestCase struct {
+		Name      string       `xml:"name,attr"`
+		ClassName string       `xml:"classname,attr"`
+		Failure   junitFailure `xml:"failure"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+func (r *junitReporter) Close() error {
+	var (
+		report        junitTestSuites
+		files, byFile = r.byFile()
+	)
+
+	for _, name := range files {
+		var suite = junitTestSuite{Name: name}
+
+		for _, d := range byFile[name] {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      fmt.Sprintf("%v:%v:%v", d.Rule, d.Pos.Line, d.Pos.Column),
+				ClassName: name,
+				Failure: junitFailure{
+					Message: d.Message,
+					Type:    d.Severity.String(),
+					Text:    junitText(d),
+				},
+			})
+		}
+
+		suite.Tests = len(suite.Cases)
+		suite.Failures = len(suite.Cases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	return writeXML(r.w, report)
+}
+
+// junitText describes the location and the fix of the diagnostic.
+func junitText(d asterisk.Diagnostic) string {
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintf(&sb, "%v:%v:%v-%v:%v: %v (%v)", d.Pos.Filename, d.Pos.Line, d.Pos.Column, d.End.Line, d.End.Column, d.Message, d.Rule)
+
+	for _, e := range d.Edits {
+		_, _ = fmt.Fprintf(&sb, "\nfix %v:%v-%v:%v: %q", e.Pos.Line, e.Pos.Column, e.End.Line, e.End.Column, e.NewText)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+// Package report writes diagnostics in machine readable formats.
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Oppodelldog/asterisk"
+)
+
+// ErrUnknownFormat is returned by New for unsupported formats.
+var ErrUnknownFormat = errors.New("unknown report format")
+
+// Reporter writes a stream of diagnostics.
+// Formats that cannot be streamed buffer the diagnostics until Close is called.
+type Reporter interface {
+	Report(d asterisk.Diagnostic) error
+	Close() error
+}
+
+var formats = map[string]func(w io.Writer, o options) Reporter{
+	"text":       func(w io.Writer, _ options) Reporter { return NewText(w) },
+	"jsonl":      func(w io.Writer, _ options) Reporter { return NewJSONLines(w) },
+	"sarif":      func(w io.Writer, o options) Reporter { return NewSARIF(w, o.rules...) },
+	"checkstyle": func(w io.Writer, _ options) Reporter { return NewCheckstyle(w) },
+	"junit":      func(w io.Writer, _ options) Reporter { return NewJUnit(w) },
+}
+
+// Option configures a Reporter created by New.
+type Option func(*options)
+
+type options struct {
+	rules asterisk.Rules
+}
+
+// WithRules describes the rules of the diagnostics by the given rules, in formats that list rules.
+func WithRules(rules asterisk.Rules) Option {
+	return func(o *options) {
+		o.rules = rules
+	}
+}
+
+// Formats returns the names of all supported formats.
+func Formats() []string {
+	var names = make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// New returns a Reporter for the given format writing to w.
+func New(format string, w io.Writer, opts ...Option) (Reporter, error) {
+	newReporter, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return newReporter(w, o), nil
+}
+
+// NewText returns a Reporter that writes one line per diagnostic.
+func NewText(w io.Writer) Reporter {
+	return &textReporter{w: w}
+}
+
+type textReporter struct {
+	w io.Writer
+}
+
+func (r *textReporter) Report(d asterisk.Diagnostic) error {
+	_, err := fmt.Fprintln(r.w, d)
+
+	return err
+}
+
+func (r *textReporter) Close() error {
+	return nil
+}
+
+// buffer collects diagnostics for formats that are written at once.
+type buffer struct {
+	diagnostics []asterisk.Diagnostic
+}
+
+func (b *buffer) Report(d asterisk.Diagnostic) error {
+	b.diagnostics = append(b.diagnostics, d)
+
+	return nil
+}
+
+// byFile groups the buffered diagnostics by file, files are returned in order of their first diagnostic.
+func (b *buffer) byFile() ([]string, map[string][]asterisk.Diagnostic) {
+	var (
+		files  []string
+		byFile = map[string][]asterisk.Diagnostic{}
+	)
+
+	for _, d := range b.diagnostics {
+		if _, ok := byFile[d.Pos.Filename]; !ok {
+			files = append(files, d.Pos.Filename)
+		}
+
+		byFile[d.Pos.Filename] = append(byFile[d.Pos.Filename], d)
+	}
+
+	return files, byFile
+}
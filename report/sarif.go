@@ -0,0 +1,250 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/Oppodelldog/asterisk"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "asterisk"
+	toolURI      = "https://github.com/Oppodelldog/asterisk"
+
+	sarifFingerprint = "asterisk/v1"
+
+	// sarifColumnKind counts columns in code points, token.Position counts them in bytes.
+	sarifColumnKind = "unicodeCodePoints"
+)
+
+var sarifLevels = map[asterisk.Severity]string{
+	asterisk.SeverityInfo:    "note",
+	asterisk.SeverityWarning: "warning",
+	asterisk.SeverityError:   "error",
+}
+
+// NewSARIF returns a Reporter that writes a SARIF 2.1.0 log on Close.
+// The given rules describe the rules of the diagnostics by their title, description and tags.
+// Columns are converted to code points using the lines of the reported files, files that cannot be read
+// keep their byte columns.
+func NewSARIF(w io.Writer, rules ...*asterisk.Rule) Reporter {
+	var byID = make(map[string]*asterisk.Rule, len(rules))
+	for _, rule := range rules {
+		byID[rule.ID] = rule
+	}
+
+	return &sarifReporter{w: w, rules: byID, lines: map[string][][]byte{}}
+}
+
+type sarifReporter struct {
+	buffer
+	w     io.Writer
+	rules map[string]*asterisk.Rule
+	// lines holds the lines of the reported files, it holds nil for files that cannot be read.
+	lines map[string][][]byte
+}
+
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool       sarifTool     `json:"tool"`
+		ColumnKind string        `json:"columnKind"`
+		Results    []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string               `json:"id"`
+		Name             string               `json:"name,omitempty"`
+		ShortDescription *sarifMessage        `json:"shortDescription,omitempty"`
+		FullDescription  *sarifMessage        `json:"fullDescription,omitempty"`
+		Properties       *sarifRuleProperties `json:"properties,omitempty"`
+	}
+	sarifRuleProperties struct {
+		Category string   `json:"category,omitempty"`
+		Tags     []string `json:"tags,omitempty"`
+	}
+	sarifResult struct {
+		RuleID           string            `json:"ruleId"`
+		RuleIndex        int               `json:"ruleIndex"`
+		Level            string            `json:"level"`
+		Message          sarifMessage      `json:"message"`
+		Locations        []sarifLocation   `json:"locations"`
+		RelatedLocations []sarifLocation   `json:"relatedLocations,omitempty"`
+		Fingerprints     map[string]string `json:"partialFingerprints,omitempty"`
+		Fixes            []sarifFix        `json:"fixes,omitempty"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine,omitempty"`
+		EndColumn   int `json:"endColumn,omitempty"`
+	}
+	sarifFix struct {
+		Description     sarifMessage          `json:"description"`
+		ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+	}
+	sarifArtifactChange struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Replacements     []sarifReplacement    `json:"replacements"`
+	}
+	sarifReplacement struct {
+		DeletedRegion   sarifRegion  `json:"deletedRegion"`
+		InsertedContent sarifMessage `json:"insertedContent"`
+	}
+)
+
+func (r *sarifReporter) Close() error {
+	var (
+		run       = sarifRun{ColumnKind: sarifColumnKind, Results: []sarifResult{}}
+		ruleIndex = map[string]int{}
+	)
+
+	run.Tool.Driver = sarifDriver{Name: toolName, InformationURI: toolURI, Rules: []sarifRule{}}
+
+	for _, d := range r.diagnostics {
+		idx, ok := ruleIndex[d.Rule]
+		if !ok {
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndex[d.Rule] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, r.sarifRuleOf(d.Rule))
+		}
+
+		var result = sarifResult{
+			RuleID:    d.Rule,
+			RuleIndex: idx,
+			Level:     sarifLevels[d.Severity],
+			Message:   sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{r.sarifLocationOf(d.Pos, d.End)},
+		}
+
+		if d.Fingerprint != "" {
+			result.Fingerprints = map[string]string{sarifFingerprint: d.Fingerprint}
+		}
+
+		for _, related := range d.Related {
+			result.RelatedLocations = append(result.RelatedLocations, r.sarifLocationOf(related, token.Position{}))
+		}
+
+		if len(d.Edits) > 0 {
+			var change = sarifArtifactChange{ArtifactLocation: sarifArtifactLocation{URI: uri(d.Pos.Filename)}}
+
+			for _, e := range d.Edits {
+				change.Replacements = append(change.Replacements, sarifReplacement{
+					DeletedRegion:   r.sarifRegionOf(e.Pos, e.End),
+					InsertedContent: sarifMessage{Text: e.NewText},
+				})
+			}
+
+			result.Fixes = []sarifFix{{Description: sarifMessage{Text: d.Message}, ArtifactChanges: []sarifArtifactChange{change}}}
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	var enc = json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// sarifRuleOf describes the rule with the given id, rules that were not given to NewSARIF are described by their id.
+func (r *sarifReporter) sarifRuleOf(id string) sarifRule {
+	var (
+		rule, ok = r.rules[id]
+		s        = sarifRule{ID: id}
+	)
+
+	if !ok {
+		return s
+	}
+
+	if rule.Title != "" {
+		s.Name = rule.Title
+		s.ShortDescription = &sarifMessage{Text: rule.Title}
+	}
+
+	if rule.Description != "" {
+		s.FullDescription = &sarifMessage{Text: rule.Description}
+	}
+
+	if rule.Category != "" || len(rule.Tags) > 0 {
+		s.Properties = &sarifRuleProperties{Category: rule.Category, Tags: rule.Tags}
+	}
+
+	return s
+}
+
+func (r *sarifReporter) sarifLocationOf(pos, end token.Position) sarifLocation {
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: uri(pos.Filename)},
+			Region:           r.sarifRegionOf(pos, end),
+		},
+	}
+}
+
+func (r *sarifReporter) sarifRegionOf(pos, end token.Position) sarifRegion {
+	return sarifRegion{
+		StartLine:   pos.Line,
+		StartColumn: r.column(pos),
+		EndLine:     end.Line,
+		EndColumn:   r.column(end),
+	}
+}
+
+// column converts the byte column of the position to a code point column.
+func (r *sarifReporter) column(pos token.Position) int {
+	if pos.Line < 1 || pos.Column < 1 {
+		return pos.Column
+	}
+
+	lines, ok := r.lines[pos.Filename]
+	if !ok {
+		if src, err := os.ReadFile(pos.Filename); err == nil {
+			lines = bytes.SplitAfter(src, []byte("\n"))
+		}
+
+		r.lines[pos.Filename] = lines
+	}
+
+	if pos.Line > len(lines) || pos.Column-1 > len(lines[pos.Line-1]) {
+		return pos.Column
+	}
+
+	return utf8.RuneCount(lines[pos.Line-1][:pos.Column-1]) + 1
+}
+
+func uri(filename string) string {
+	return filepath.ToSlash(filename)
+}
@@ -0,0 +1,371 @@
+package asterisk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"reflect"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrInvalidRule is returned for rules that cannot be compiled.
+	ErrInvalidRule = errors.New("invalid rule")
+	// ErrDuplicateRule is returned if two rules share the same id.
+	ErrDuplicateRule = errors.New("duplicate rule id")
+)
+
+// checkKey is the key Check rules select the matched node by.
+const checkKey = "node"
+
+// nodeKinds holds the names of the ast node types constraints may require.
+var nodeKinds = kindNames(
+	new(ast.BadExpr), new(ast.Ident), new(ast.Ellipsis), new(ast.BasicLit), new(ast.FuncLit),
+	new(ast.CompositeLit), new(ast.ParenExpr), new(ast.SelectorExpr), new(ast.IndexExpr),
+	new(ast.IndexListExpr), new(ast.SliceExpr), new(ast.TypeAssertExpr), new(ast.CallExpr),
+	new(ast.StarExpr), new(ast.UnaryExpr), new(ast.BinaryExpr), new(ast.KeyValueExpr),
+	new(ast.ArrayType), new(ast.StructType), new(ast.FuncType), new(ast.InterfaceType), new(ast.MapType),
+	new(ast.ChanType),
+	new(ast.BadStmt), new(ast.DeclStmt), new(ast.EmptyStmt), new(ast.LabeledStmt), new(ast.ExprStmt),
+	new(ast.SendStmt), new(ast.IncDecStmt), new(ast.AssignStmt), new(ast.GoStmt), new(ast.DeferStmt),
+	new(ast.ReturnStmt), new(ast.BranchStmt), new(ast.BlockStmt), new(ast.IfStmt), new(ast.CaseClause),
+	new(ast.SwitchStmt), new(ast.TypeSwitchStmt), new(ast.CommClause), new(ast.SelectStmt), new(ast.ForStmt),
+	new(ast.RangeStmt),
+	new(ast.ImportSpec), new(ast.ValueSpec), new(ast.TypeSpec),
+	new(ast.BadDecl), new(ast.GenDecl), new(ast.FuncDecl),
+	new(ast.Comment), new(ast.CommentGroup), new(ast.Field), new(ast.FieldList), new(ast.File),
+)
+
+func kindNames(nodes ...ast.Node) map[string]bool {
+	var names = make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		names[reflect.TypeOf(n).Elem().Name()] = true
+	}
+
+	return names
+}
+
+// ReportFunc receives the diagnostics of matching rules.
+type ReportFunc func(Diagnostic)
+
+// Rule is a declarative rule, usually loaded from a rule file.
+// Pattern is a CodePattern, Message a Message template referencing the variables of the pattern.
+// If Replacement is set, the matched node is replaced by it, variables are substituted by the source text
+// of their captured nodes.
+// Rules written in Go set Check instead of Pattern, their Message references the matched node as {{.node}}.
+// Title, Description, Category, Tags and Examples document the rule, Severity is its default severity.
+type Rule struct {
+	ID          string                `json:"id" yaml:"id"`
+	Title       string                `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Category    string                `json:"category,omitempty" yaml:"category,omitempty"`
+	Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Examples    Examples              `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Pattern     string                `json:"pattern" yaml:"pattern"`
+	Where       map[string]Constraint `json:"where,omitempty" yaml:"where,omitempty"`
+	Message     string                `json:"message" yaml:"message"`
+	Severity    Severity              `json:"severity" yaml:"severity"`
+	Replacement string                `json:"replacement,omitempty" yaml:"replacement,omitempty"`
+	Check       *Check                `json:"-" yaml:"-"`
+
+	pattern *CodePattern
+	message *Message
+	where   map[string]*regexp.Regexp
+}
+
+// Constraint restricts the nodes a variable of a pattern captures.
+type Constraint struct {
+	// Matches is a regular expression the source text of the captured node has to match.
+	Matches string `json:"matches,omitempty" yaml:"matches,omitempty"`
+	// Kind is the name of the ast node type of the captured node, e.g. "BasicLit".
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
+// Examples show code that violates a rule and code that does not.
+type Examples struct {
+	Good []string `json:"good,omitempty" yaml:"good,omitempty"`
+	Bad  []string `json:"bad,omitempty" yaml:"bad,omitempty"`
+}
+
+// Check implements a rule in Go. Condition returns the condition matching the offending nodes of a file,
+// Fix returns the edits fixing a matched node, it is optional.
+type Check struct {
+	Condition func(p *Positions) NodeCondition
+	Fix       func(p *Positions, n ast.Node) []TextEdit
+}
+
+// Rules holds multiple rules.
+type Rules []*Rule
+
+type ruleFile struct {
+	Rules Rules `json:"rules" yaml:"rules"`
+}
+
+// LoadRules reads and compiles the rules of the given YAML or JSON rule file.
+func LoadRules(file string) (Rules, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", file, err)
+	}
+
+	return rules, nil
+}
+
+// ParseRules parses and compiles rules from the given YAML or JSON data.
+func ParseRules(data []byte) (Rules, error) {
+	var (
+		f   ruleFile
+		dec = yaml.NewDecoder(bytes.NewReader(data))
+	)
+
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if err := f.Rules.Compile(); err != nil {
+		return nil, err
+	}
+
+	return f.Rules, nil
+}
+
+// Compile compiles all rules, it has to be called before the rules are used.
+func (rs Rules) Compile() error {
+	var ids = map[string]bool{}
+
+	for _, r := range rs {
+		if ids[r.ID] {
+			return fmt.Errorf("%w: %v", ErrDuplicateRule, r.ID)
+		}
+
+		ids[r.ID] = true
+
+		if err := r.Compile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Compile parses the pattern, message and constraints of the rule.
+func (r *Rule) Compile() error {
+	if r.ID == "" {
+		return fmt.Errorf("%w: missing id", ErrInvalidRule)
+	}
+
+	message, err := ParseMessage(r.Message)
+	if err != nil {
+		return fmt.Errorf("%w %v: %v", ErrInvalidRule, r.ID, err)
+	}
+
+	if r.Check != nil {
+		if r.Check.Condition == nil || r.Pattern != "" {
+			return fmt.Errorf("%w %v: a check requires a condition and no pattern", ErrInvalidRule, r.ID)
+		}
+
+		if err := checkKeys(message, []string{checkKey}); err != nil {
+			return fmt.Errorf("%w %v: %v", ErrInvalidRule, r.ID, err)
+		}
+
+		r.message = message
+
+		return nil
+	}
+
+	pattern, err := ParseCodePattern(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("%w %v: %v", ErrInvalidRule, r.ID, err)
+	}
+
+	if err := checkKeys(message, pattern.Vars()); err != nil {
+		return fmt.Errorf("%w %v: %v", ErrInvalidRule, r.ID, err)
+	}
+
+	var where = map[string]*regexp.Regexp{}
+
+	for name, c := range r.Where {
+		if !contains(pattern.Vars(), name) {
+			return fmt.Errorf("%w %v: constraint on unknown variable $%v", ErrInvalidRule, r.ID, name)
+		}
+
+		if c.Kind != "" && !nodeKinds[c.Kind] {
+			return fmt.Errorf("%w %v: constraint on $%v has unknown kind %q", ErrInvalidRule, r.ID, name, c.Kind)
+		}
+
+		if where[name], err = regexp.Compile(c.Matches); err != nil {
+			return fmt.Errorf("%w %v: %v", ErrInvalidRule, r.ID, err)
+		}
+	}
+
+	if r.Replacement != "" {
+		if _, err := parseCode(r.Replacement); err != nil {
+			return fmt.Errorf("%w %v: replacement: %v", ErrInvalidRule, r.ID, err)
+		}
+
+		for _, v := range varRegex.FindAllString(r.Replacement, -1) {
+			if !contains(pattern.Vars(), v[1:]) {
+				return fmt.Errorf("%w %v: replacement uses unknown variable %v", ErrInvalidRule, r.ID, v)
+			}
+		}
+	}
+
+	r.pattern, r.message, r.where = pattern, message, where
+
+	return nil
+}
+
+// Matchers returns PatternMatchers that report each match of the rules in the given file.
+func (rs Rules) Matchers(fileSet *token.FileSet, file *ast.File, report ReportFunc) PatternMatchers {
+	var pms = make(PatternMatchers, len(rs))
+	for i, r := range rs {
+		pms[i] = r.Matcher(fileSet, file, report)
+	}
+
+	return pms
+}
+
+// Matcher returns a Matcher that reports each match of the rule in the given file.
+// If the rule has a replacement or fix, the Diagnostic contains the edits fixing the matched node.
+// Rules with a Check require the file to be parsed with comments.
+func (r *Rule) Matcher(fileSet *token.FileSet, file *ast.File, report ReportFunc) *Matcher {
+	if r.Check != nil {
+		return r.matcher(fileSet, file, report, NodeCondition{})
+	}
+
+	return r.matcher(fileSet, file, report, r.pattern.Condition())
+}
+
+// matcher returns the Matcher of the rule, pattern is the condition of its code pattern.
+func (r *Rule) matcher(fileSet *token.FileSet, file *ast.File, report ReportFunc, pattern NodeCondition) *Matcher {
+	var (
+		matched ast.Node
+		cond    NodeCondition
+		fix     func(s NodeSelections) ([]TextEdit, error)
+	)
+
+	if r.Check != nil {
+		var p = NewPositions(fileSet, file)
+
+		cond = Select(r.Check.Condition(p), checkKey)
+
+		if r.Check.Fix != nil {
+			fix = func(NodeSelections) ([]TextEdit, error) {
+				return r.Check.Fix(p, matched), nil
+			}
+		}
+	} else {
+		// the constraints only narrow the pattern, so the condition accepts the kinds of the pattern.
+		cond = narrowed(pattern, func(ev *evaluation, n ast.Node) bool {
+			return pattern.match(ev, n) && r.satisfied(fileSet, ev.state.selections)
+		})
+
+		if r.Replacement != "" {
+			fix = func(s NodeSelections) ([]TextEdit, error) {
+				text, err := r.replacement(fileSet, s)
+				if err != nil {
+					return nil, err
+				}
+
+				return []TextEdit{{Pos: fileSet.Position(matched.Pos()), End: fileSet.Position(matched.End()), NewText: text}}, nil
+			}
+		}
+	}
+
+	return NewPattern(
+		r.ID,
+		[]NodeCondition{
+			narrowed(cond, func(ev *evaluation, n ast.Node) bool {
+				if !cond.match(ev, n) {
+					return false
+				}
+
+				matched = n
+
+				return true
+			}),
+		},
+		func(s NodeSelections) error {
+			msg, err := r.message.Render(fileSet, s)
+			if err != nil {
+				return err
+			}
+
+			var d = NewDiagnostic(fileSet, matched, r.ID, r.Severity, msg)
+
+			if fix != nil {
+				if d.Edits, err = fix(s); err != nil {
+					return err
+				}
+			}
+
+			report(d)
+
+			return nil
+		},
+	).Matcher()
+}
+
+// checkKeys returns an error if the message refers to a key that is not given.
+func checkKeys(message *Message, keys []string) error {
+	for _, key := range message.Keys() {
+		if !contains(keys, key) {
+			return fmt.Errorf("message uses unknown variable $%v", key)
+		}
+	}
+
+	return nil
+}
+
+// satisfied checks the constraints of the rule against the captured nodes.
+func (r *Rule) satisfied(fileSet *token.FileSet, s NodeSelections) bool {
+	for name, c := range r.Where {
+		var node = **s[name][0]
+
+		if c.Kind != "" && reflect.TypeOf(node).Elem().Name() != c.Kind {
+			return false
+		}
+
+		if c.Matches != "" {
+			text, err := SourceText(fileSet, node)
+			if err != nil || !r.where[name].MatchString(text) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// replacement renders the replacement of the rule using the source text of the captured nodes.
+func (r *Rule) replacement(fileSet *token.FileSet, s NodeSelections) (string, error) {
+	var err error
+
+	text := varRegex.ReplaceAllStringFunc(r.Replacement, func(v string) string {
+		nodes, ok := s[v[1:]]
+		if !ok {
+			return v
+		}
+
+		var text string
+		if text, err = SourceText(fileSet, **nodes[0]); err != nil {
+			return v
+		}
+
+		return text
+	})
+
+	return text, err
+}
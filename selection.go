@@ -17,6 +17,11 @@ func (s NodeSelections) Ident(key string) *ast.Ident {
 	return (**s[key][0]).(*ast.Ident)
 }
 
+// Expr returns the ast.Expr that was selected using the given key.
+func (s NodeSelections) Expr(key string) ast.Expr {
+	return (**s[key][0]).(ast.Expr)
+}
+
 // CallExpr returns a pointer to the ast.CallExpr that was selected using the given key.
 func (s NodeSelections) CallExpr(key string) *ast.CallExpr {
 	return (**s[key][0]).(*ast.CallExpr)
@@ -42,10 +47,73 @@ func (s NodeSelections) IfStmt(key string) *ast.IfStmt {
 	return (**s[key][0]).(*ast.IfStmt)
 }
 
+// Clone returns a deep copy of the node that was selected using the given key.
+// Captured nodes should be cloned before they are inserted somewhere else in the tree.
+func (s NodeSelections) Clone(key string, opts ...CloneOption) ast.Node {
+	return Clone(**s[key][0], opts...)
+}
+
+// Select will select the visited node for the given key if the given condition matches.
+// The node is selected for the current match, the walk passes the selections of a match to the processMatch
+// function of its Pattern, so the condition can be shared by concurrent walks.
+// It accepts the kinds of the given condition.
+func Select(c NodeCondition, key string) NodeCondition {
+	return narrowed(c, func(ev *evaluation, n ast.Node) bool {
+		var res = c.match(ev, n)
+
+		if res {
+			ev.selectNodes(key, n)
+		}
+
+		return res
+	})
+}
+
+// Selects will select the visited nodes for the given key if the given condition matches,
+// they are selected for the current match like Select does.
+func Selects(c NodesCondition, key string) NodesCondition {
+	return NodesCondition{eval: func(ev *evaluation, n []ast.Node) bool {
+		var res = c.match(ev, n)
+
+		if res {
+			ev.selectNodes(key, n...)
+		}
+
+		return res
+	}}
+}
+
+// selectNodes selects the given nodes for the current match, nodes selected outside of matches are dropped.
+// The nodes are copied, since the slices passed to NodesConditions are reused.
+func (ev *evaluation) selectNodes(key string, nodes ...ast.Node) {
+	if ev.state == nil {
+		return
+	}
+
+	if ev.state.selections == nil {
+		ev.state.selections = NodeSelections{}
+	}
+
+	var selected = make([]**ast.Node, len(nodes))
+
+	for i := range nodes {
+		var (
+			node = nodes[i]
+			n1   = &node
+		)
+
+		selected[i] = &n1
+	}
+
+	ev.state.selections[key] = selected
+}
+
 // Select will select the visited node for the given key if the given condition matches.
+// It accepts the kinds of the given condition. Unlike the function Select, it selects into s,
+// which is shared by all walks using the condition, so concurrent walks need conditions of their own selections.
 func (s NodeSelections) Select(c NodeCondition, key string) NodeCondition {
-	return func(n ast.Node) bool {
-		var res = c(n)
+	return narrowed(c, func(ev *evaluation, n ast.Node) bool {
+		var res = c.match(ev, n)
 
 		if res {
 			var (
@@ -58,7 +126,7 @@ func (s NodeSelections) Select(c NodeCondition, key string) NodeCondition {
 		}
 
 		return res
-	}
+	})
 }
 
 // ExprStmt returns a pointer to the ast.ExprStmt that was selected using the given key.
@@ -77,16 +145,21 @@ func (s NodeSelections) ImportSpecs(key string) []*ast.ImportSpec {
 
 // Select will select the visited nodes for the given key if the given condition matches.
 func (s NodeSelections) Selects(c NodesCondition, key string) NodesCondition {
-	return func(n []ast.Node) bool {
-		var res = c(n)
+	return NodesCondition{eval: func(ev *evaluation, n []ast.Node) bool {
+		var res = c.match(ev, n)
 
 		if res {
 			var (
 				nodes []**ast.Node
 			)
 
+			// the slice is reused once the condition returned, so the nodes are copied.
 			for i := range n {
-				var n1 = &n[i]
+				var (
+					node = n[i]
+					n1   = &node
+				)
+
 				nodes = append(nodes, &n1)
 			}
 
@@ -94,5 +167,5 @@ func (s NodeSelections) Selects(c NodesCondition, key string) NodesCondition {
 		}
 
 		return res
-	}
+	}}
 }
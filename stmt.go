@@ -0,0 +1,103 @@
+package asterisk
+
+import (
+	"errors"
+	"go/ast"
+)
+
+var (
+	// ErrNoStmtList is returned if a statement list was edited on a node that has no statement list.
+	ErrNoStmtList = errors.New("node has no statement list")
+	// ErrStmtNotFound is returned if the statement to edit is not part of the statement list.
+	ErrStmtNotFound = errors.New("statement not found in statement list")
+)
+
+// StmtList returns a pointer to the statement list of a ast.BlockStmt, ast.CaseClause or ast.CommClause.
+// For any other node nil is returned.
+func StmtList(n ast.Node) *[]ast.Stmt {
+	switch e := n.(type) {
+	case *ast.BlockStmt:
+		return &e.List
+	case *ast.CaseClause:
+		return &e.Body
+	case *ast.CommClause:
+		return &e.Body
+	}
+
+	return nil
+}
+
+// StmtParent returns the ast.BlockStmt, ast.CaseClause or ast.CommClause below root whose
+// statement list contains the given statement.
+func StmtParent(root ast.Node, stmt ast.Stmt) ast.Node {
+	var parent ast.Node
+
+	ast.Inspect(root, func(n ast.Node) bool {
+		if parent != nil {
+			return false
+		}
+
+		if list := StmtList(n); list != nil && indexOf(*list, stmt) >= 0 {
+			parent = n
+		}
+
+		return parent == nil
+	})
+
+	return parent
+}
+
+// ReplaceStmt replaces the statement old in the statement list of parent by the given statements.
+func ReplaceStmt(parent ast.Node, old ast.Stmt, stmts ...ast.Stmt) error {
+	return splice(parent, old, 0, 1, stmts)
+}
+
+// DeleteStmt removes the statement from the statement list of parent.
+func DeleteStmt(parent ast.Node, stmt ast.Stmt) error {
+	return splice(parent, stmt, 0, 1, nil)
+}
+
+// InsertBefore inserts the given statements in front of the statement at in the statement list of parent.
+func InsertBefore(parent ast.Node, at ast.Stmt, stmts ...ast.Stmt) error {
+	return splice(parent, at, 0, 0, stmts)
+}
+
+// InsertAfter inserts the given statements behind the statement at in the statement list of parent.
+func InsertAfter(parent ast.Node, at ast.Stmt, stmts ...ast.Stmt) error {
+	return splice(parent, at, 1, 0, stmts)
+}
+
+// splice removes n statements starting at the position of stmt plus offset and inserts the given statements there.
+// The statement list is always replaced by a new slice, so a running ast.Inspect is not affected by the edit.
+func splice(parent ast.Node, stmt ast.Stmt, offset, n int, stmts []ast.Stmt) error {
+	var list = StmtList(parent)
+	if list == nil {
+		return ErrNoStmtList
+	}
+
+	var idx = indexOf(*list, stmt)
+	if idx < 0 {
+		return ErrStmtNotFound
+	}
+
+	idx += offset
+
+	var edited = make([]ast.Stmt, 0, len(*list)-n+len(stmts))
+	edited = append(edited, (*list)[:idx]...)
+	edited = append(edited, stmts...)
+	edited = append(edited, (*list)[idx+n:]...)
+
+	*list = edited
+
+	return nil
+}
+
+func indexOf(list []ast.Stmt, stmt ast.Stmt) int {
+	for i := range list {
+		if list[i] == stmt {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,224 @@
+package asterisk
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+const (
+	ignoreDirective     = "//asterisk:ignore"
+	ignoreFileDirective = "//asterisk:ignore-file"
+	allRules            = "*"
+	reasonSeparator     = "--"
+
+	unusedSuppressionRule = "unused-suppression"
+)
+
+// Suppression is an inline directive that silences diagnostics.
+//
+//	//asterisk:ignore [rule-id[,rule-id]] [-- reason]
+//
+// suppresses diagnostics of the given rules, or of all rules, on the line of the comment, or on the following line
+// if the comment stands on its own line. Placed in the doc comment of a declaration, it suppresses
+// the diagnostics of the whole declaration.
+//
+//	//asterisk:ignore-file [rule-id[,rule-id]] [-- reason]
+//
+// suppresses diagnostics of the given rules, or of all rules, in the whole file.
+// A directive with more than the rule list before the reason separator is invalid, it suppresses nothing
+// and is reported like an unused suppression.
+type Suppression struct {
+	Pos    token.Position
+	Rules  []string
+	Reason string
+
+	from, to int
+	used     bool
+	invalid  bool
+}
+
+func (s *Suppression) String() string {
+	return fmt.Sprintf("%v: %v", s.Pos, s.Diagnostic().Message)
+}
+
+// Diagnostic returns a Diagnostic reporting the suppression as unused, or as invalid.
+func (s *Suppression) Diagnostic() Diagnostic {
+	var msg = "unused suppression of " + strings.Join(s.Rules, ",")
+	if s.invalid {
+		msg = "invalid suppression, separate the reason from the rules by " + reasonSeparator
+	}
+
+	return Diagnostic{
+		Rule:     unusedSuppressionRule,
+		Severity: SeverityWarning,
+		Message:  msg,
+		Pos:      s.Pos,
+		End:      s.Pos,
+	}
+}
+
+func (s *Suppression) suppresses(d Diagnostic) bool {
+	if s.invalid || d.Pos.Filename != s.Pos.Filename || d.Pos.Line < s.from || d.Pos.Line > s.to {
+		return false
+	}
+
+	return contains(s.Rules, allRules) || contains(s.Rules, d.Rule)
+}
+
+// refersTo reports whether the suppression refers to all rules or to the name of one of the matchers.
+// Invalid suppressions refer to any matchers.
+func (s *Suppression) refersTo(pms PatternMatchers) bool {
+	if s.invalid || contains(s.Rules, allRules) {
+		return true
+	}
+
+	for _, pm := range pms {
+		if contains(s.Rules, pm.Name()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Suppressions holds the suppression directives of a file.
+type Suppressions []*Suppression
+
+// ParseSuppressions reads the suppression directives from the comments of the given file.
+func ParseSuppressions(fileSet *token.FileSet, file *ast.File) Suppressions {
+	var (
+		s        Suppressions
+		docs     = map[*ast.CommentGroup]ast.Decl{}
+		codeLine = map[int]token.Pos{}
+	)
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			docs[d.Doc] = d
+		case *ast.GenDecl:
+			docs[d.Doc] = d
+		}
+	}
+
+	// remember the first code position of each line to distinguish trailing from standalone comments.
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.CommentGroup:
+			return false
+		case *ast.File:
+			return true
+		}
+
+		line := fileSet.Position(n.Pos()).Line
+		if p, ok := codeLine[line]; !ok || n.Pos() < p {
+			codeLine[line] = n.Pos()
+		}
+
+		return true
+	})
+
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			suppression, isFile := parseSuppression(fileSet, c)
+			if suppression == nil {
+				continue
+			}
+
+			switch decl, ok := docs[group]; {
+			case isFile:
+				suppression.from, suppression.to = 1, fileSet.File(file.Pos()).LineCount()
+			case ok && decl != nil:
+				suppression.from = fileSet.Position(decl.Pos()).Line
+				suppression.to = fileSet.Position(decl.End()).Line
+			default:
+				suppression.from, suppression.to = suppression.Pos.Line, suppression.Pos.Line
+				if p, ok := codeLine[suppression.Pos.Line]; !ok || p > c.Pos() {
+					suppression.to++
+				}
+			}
+
+			s = append(s, suppression)
+		}
+	}
+
+	return s
+}
+
+// parseSuppression parses the given comment, it returns nil if the comment is no directive.
+func parseSuppression(fileSet *token.FileSet, c *ast.Comment) (*Suppression, bool) {
+	var (
+		text   string
+		isFile bool
+	)
+
+	switch {
+	case strings.HasPrefix(c.Text, ignoreFileDirective):
+		text, isFile = strings.TrimPrefix(c.Text, ignoreFileDirective), true
+	case strings.HasPrefix(c.Text, ignoreDirective):
+		text = strings.TrimPrefix(c.Text, ignoreDirective)
+	default:
+		return nil, false
+	}
+
+	if text != "" && !strings.HasPrefix(text, " ") {
+		return nil, false
+	}
+
+	var (
+		rules, reason, _ = strings.Cut(text, " "+reasonSeparator)
+		fields           = strings.Fields(rules)
+		suppression      = &Suppression{Pos: fileSet.Position(c.Pos()), Rules: []string{allRules}}
+	)
+
+	switch len(fields) {
+	case 0:
+	case 1:
+		suppression.Rules = strings.Split(fields[0], ",")
+	default:
+		suppression.Rules, suppression.invalid = fields, true
+	}
+
+	suppression.Reason = strings.TrimSpace(reason)
+
+	return suppression, isFile
+}
+
+// Suppressed reports whether the diagnostic is suppressed by a directive.
+func (s Suppressions) Suppressed(d Diagnostic) bool {
+	var suppressed bool
+
+	for _, suppression := range s {
+		if suppression.suppresses(d) {
+			suppression.used = true
+			suppressed = true
+		}
+	}
+
+	return suppressed
+}
+
+// Filter returns a ReportFunc that passes only diagnostics that are not suppressed to report.
+// Since rewrites are carried by diagnostics, suppressed findings are neither reported nor fixed.
+func (s Suppressions) Filter(report ReportFunc) ReportFunc {
+	return func(d Diagnostic) {
+		if !s.Suppressed(d) {
+			report(d)
+		}
+	}
+}
+
+// Unused returns the directives that did not suppress any diagnostic.
+func (s Suppressions) Unused() Suppressions {
+	var unused Suppressions
+
+	for _, suppression := range s {
+		if !suppression.used {
+			unused = append(unused, suppression)
+		}
+	}
+
+	return unused
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+	"github.com/Oppodelldog/asterisk/analyzer"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	var s1 = NodeSelections{}
+
+	a := analyzer.New("example", "reports example patterns",
+		analyzer.Rule{
+			Matcher: New(
+				[]NodeCondition{CallExpr(Ident("panic"), IgnoreNodes())},
+				func() error { return nil },
+			).Named("no-panic"),
+			Message: "do not panic",
+		},
+		analyzer.Rule{
+			Matcher: New(
+				[]NodeCondition{
+					CallExpr(SelectorExpr(Ident("strings"), s1.Select(Ident("ToUpper"), "method")), IgnoreNodes()),
+				},
+				func() error {
+					s1.Ident("method").Name = "ToLower"
+
+					return nil
+				},
+			).Named("to-lower"),
+			Message:    "use strings.ToLower instead of strings.{{.method}}",
+			Selections: s1,
+		},
+	)
+
+	dir, err := filepath.Abs(filepath.Join("resources", "testdata"))
+	FailOnError(t, err)
+
+	analysistest.RunWithSuggestedFixes(t, dir, a, "example")
+}
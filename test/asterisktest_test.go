@@ -0,0 +1,86 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Oppodelldog/asterisk"
+	"github.com/Oppodelldog/asterisk/asterisktest"
+)
+
+func TestAsterisktest_Run(t *testing.T) {
+	rules, err := asterisk.LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	results := asterisktest.Run(t, filepath.Join("resources", "testdata", "asterisktest", "pass"), rules)
+
+	if len(results) != 1 || len(results[0].Diagnostics) != 3 {
+		t.Fatalf("expected 3 diagnostics in 1 fixture, got: %+v", results)
+	}
+}
+
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Helper() {}
+
+func TestAsterisktest_Run_failures(t *testing.T) {
+	rules, err := asterisk.LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		r    = &recorder{}
+		file = filepath.Join("resources", "testdata", "asterisktest", "fail", "logrus.go")
+	)
+
+	asterisktest.Run(r, filepath.Dir(file), rules)
+
+	var got []string
+	for _, e := range r.errors {
+		got = append(got, strings.SplitN(e, "\n", 2)[0])
+	}
+
+	want := []string{
+		file + ":6:2: unexpected diagnostic: use zerolog instead of logrus.Error",
+		file + ":7:2: unexpected diagnostic: use zerolog instead of logrus.Info",
+		file + `:7:22: no diagnostic was reported matching "use zerolog instead of logrus.Warn"`,
+		file + ": rewritten fixture does not match logrus.go.golden:",
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected errors:\nwant: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestAsterisktest_Run_update(t *testing.T) {
+	rules, err := asterisk.LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		dir    = t.TempDir()
+		file   = filepath.Join(dir, "logrus.go")
+		golden = file + ".golden"
+		pass   = filepath.Join("testdata", "asterisktest", "pass")
+	)
+
+	FailOnError(t, os.WriteFile(file, MustReadFile(t, filepath.Join(pass, "logrus.go")), 0600))
+	FailOnError(t, os.WriteFile(golden, []byte("outdated"), 0600))
+
+	t.Setenv("ASTERISK_UPDATE", "1")
+	asterisktest.Run(t, dir, rules)
+
+	got, err := os.ReadFile(golden)
+	FailOnError(t, err)
+
+	AssertEquals(t, string(MustReadFile(t, filepath.Join(pass, "logrus.go.golden"))), string(got))
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"errors"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestBaseline(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		file     = filepath.Join(t.TempDir(), "baseline.json")
+		existing = checkBaselineFile(t, rules, "baseline1.go.txt", nil)
+	)
+
+	FailOnError(t, NewBaseline(existing).Save(file))
+
+	baseline, err := LoadBaseline(file)
+	FailOnError(t, err)
+
+	var got []string
+	for _, d := range checkBaselineFile(t, rules, "baseline2.go.txt", baseline.Filter) {
+		got = append(got, d.String())
+	}
+
+	want := []string{
+		"baseline.go:13:2: error: use zerolog instead of logrus.Error (logrus-call)",
+		"baseline.go:14:7: info: double a.b (double-operand)",
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected diagnostics:\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+func TestLoadBaseline_version(t *testing.T) {
+	var file = filepath.Join(t.TempDir(), "baseline.json")
+
+	FailOnError(t, os.WriteFile(file, []byte(`{"version": 99}`), 0600))
+
+	if _, err := LoadBaseline(file); !errors.Is(err, ErrBaselineVersion) {
+		t.Fatalf("expected ErrBaselineVersion, got %v", err)
+	}
+}
+
+func TestFingerprint_ignoresPosition(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		before = checkBaselineFile(t, rules, "baseline1.go.txt", nil)
+		after  = checkBaselineFile(t, rules, "baseline2.go.txt", nil)
+	)
+
+	if before[0].Fingerprint == "" || before[0].Fingerprint != after[0].Fingerprint {
+		t.Fatalf("expected fingerprint to be stable, got: %q and %q", before[0].Fingerprint, after[0].Fingerprint)
+	}
+
+	if after[0].Fingerprint == after[2].Fingerprint {
+		t.Fatalf("expected different findings to have different fingerprints, got: %q", after[0].Fingerprint)
+	}
+}
+
+// checkBaselineFile reports the diagnostics of the given resource file as if it was named baseline.go.
+func checkBaselineFile(t *testing.T, rules Rules, resource string, filter func(ReportFunc) ReportFunc) []Diagnostic {
+	var (
+		fileSet     = token.NewFileSet()
+		f           = MustParse(t, fileSet, "baseline.go", MustReadFile(t, resource))
+		diagnostics []Diagnostic
+		report      ReportFunc = func(d Diagnostic) {
+			diagnostics = append(diagnostics, d)
+		}
+	)
+
+	if filter != nil {
+		report = filter(report)
+	}
+
+	FailOnError(t, Walk(f, rules.Matchers(fileSet, f, report)))
+
+	return diagnostics
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"go/ast"
+	"runtime"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+// BenchmarkConditions runs single conditions against all nodes of a generated file and reports
+// the time and allocations per node, run it with: go test -bench Conditions -run ^$ .
+func BenchmarkConditions(b *testing.B) {
+	var (
+		f     = generatedFile(b, 500)
+		nodes []ast.Node
+	)
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n != nil {
+			nodes = append(nodes, n)
+		}
+
+		return true
+	})
+
+	for _, bm := range []struct {
+		name      string
+		condition NodeCondition
+	}{
+		{name: "Type", condition: Type(new(ast.CallExpr))},
+		{name: "Is", condition: Is[*ast.CallExpr]()},
+		{name: "IsInterface", condition: Is[ast.Expr]()},
+		{name: "CallExpr", condition: CallExpr(IgnoreNode(), Exprs([]NodeCondition{Ident("a"), Ident("b")}))},
+		{name: "BlockStmt", condition: BlockStmt(Last(Type(new(ast.ReturnStmt))))},
+		{name: "AssignStmt", condition: AssignStmt(First(Is[*ast.Ident]()), IgnoreNodes())},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			benchmarkPerNode(b, len(nodes), func() {
+				for _, n := range nodes {
+					bm.condition.Match(n)
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkWalk_transactional rewrites every call of a generated file in a transactional walk and reports the
+// time and allocations per node, run it with: go test -bench Walk_transactional -run ^$ .
+func BenchmarkWalk_transactional(b *testing.B) {
+	var (
+		f     = generatedFile(b, 500)
+		s1    = NodeSelections{}
+		nodes int
+	)
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n != nil {
+			nodes++
+		}
+
+		return true
+	})
+
+	pms := PatternMatchers{
+		New(
+			[]NodeCondition{CallExpr(SelectorExpr(IgnoreNode(), s1.Select(IgnoreNode(), "method")), IgnoreNodes())},
+			func() error {
+				var method = s1.Ident("method")
+				if method.Name == "Call" {
+					method.Name = "Called"
+				} else {
+					method.Name = "Call"
+				}
+
+				return nil
+			},
+		),
+	}
+
+	benchmarkPerNode(b, nodes, func() {
+		if err := Walk(f, pms, Transactional()); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+// benchmarkPerNode runs f b.N times and reports the time and allocations per node of a run over the given nodes.
+func benchmarkPerNode(b *testing.B, nodes int, f func()) {
+	var before, after runtime.MemStats
+
+	b.ReportAllocs()
+	runtime.ReadMemStats(&before)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		f()
+	}
+
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+
+	var visited = float64(b.N) * float64(nodes)
+
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/visited, "ns/node")
+	b.ReportMetric(float64(after.Mallocs-before.Mallocs)/visited, "allocs/node")
+}
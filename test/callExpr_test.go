@@ -23,7 +23,7 @@ func TestCallExpr(t *testing.T) {
 		s3 = NodeSelections{}
 	)
 
-	Walk(f, PatternMatchers{
+	FailOnError(t, Walk(f, PatternMatchers{
 		New(
 			[]NodeCondition{
 				Type(new(ast.ExprStmt)),
@@ -42,10 +42,12 @@ func TestCallExpr(t *testing.T) {
 					),
 				),
 			},
-			func() {
+			func() error {
 				s1.Ident("package1").Name = "zerolog"
 				s1.Ident("methodName").Name = "SetGlobalLevel"
 				s1.Ident("package2").Name = "zerolog"
+
+				return nil
 			},
 		),
 		New(
@@ -63,12 +65,14 @@ func TestCallExpr(t *testing.T) {
 					),
 				),
 			},
-			func() {
+			func() error {
 				s2.ExprStmt("call").X = createZerologCallExpr(
 					s2.Ident("methodName").Name,
 					"Msg",
-					s2.BasicLit("arg"),
+					s2.Clone("arg").(ast.Expr),
 				)
+
+				return nil
 			},
 		),
 		New(
@@ -87,16 +91,18 @@ func TestCallExpr(t *testing.T) {
 					),
 				),
 			},
-			func() {
+			func() error {
 				s3.ExprStmt("call").X = createZerologCallExpr(
 					"Info",
 					"Msgf",
 					&ast.BasicLit{Kind: token.STRING, Value: `"%v %v"`},
-					s3.BasicLit("arg1"),
-					s3.BasicLit("arg2"))
+					s3.Clone("arg1").(ast.Expr),
+					s3.Clone("arg2").(ast.Expr))
+
+				return nil
 			},
 		),
-	})
+	}))
 
 	patched := bytes.NewBuffer([]byte{})
 	FailOnError(t, printer.Fprint(patched, fileSet, f))
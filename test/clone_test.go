@@ -0,0 +1,61 @@
+package test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestClone(t *testing.T) {
+	var (
+		data    = MustReadFile(t, "if.go.txt")
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "", data)
+		fn      = f.Decls[0].(*ast.FuncDecl)
+		clone   = Clone(fn).(*ast.FuncDecl)
+	)
+
+	if clone == fn || clone.Body == fn.Body || clone.Body.List[0] == fn.Body.List[0] {
+		t.Fatal("expected clone not to share nodes with the original")
+	}
+
+	if clone.Name.Obj != nil {
+		t.Fatal("expected object references to be dropped")
+	}
+
+	AssertEquals(t, printNode(t, fileSet, fn), printNode(t, fileSet, clone))
+
+	clone.Name.Name = "Changed"
+
+	if fn.Name.Name != "Got" {
+		t.Fatal("expected modification of the clone not to affect the original")
+	}
+}
+
+func TestClone_resetPositions(t *testing.T) {
+	var (
+		data    = MustReadFile(t, "if.go.txt")
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "", data)
+		clone   = Clone(f.Decls[0], ResetPositions())
+	)
+
+	ast.Inspect(clone, func(n ast.Node) bool {
+		if n != nil && n.Pos() != token.NoPos {
+			t.Fatalf("expected position of %T to be reset, got %v", n, n.Pos())
+		}
+
+		return true
+	})
+}
+
+func printNode(t *testing.T, fileSet *token.FileSet, n ast.Node) string {
+	buf := bytes.NewBuffer([]byte{})
+	FailOnError(t, printer.Fprint(buf, fileSet, n))
+
+	return buf.String()
+}
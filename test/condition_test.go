@@ -0,0 +1,29 @@
+package test
+
+import (
+	"go/ast"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestIs(t *testing.T) {
+	var (
+		call   ast.Node = &ast.CallExpr{}
+		stmt   ast.Node = &ast.ExprStmt{X: &ast.CallExpr{}}
+		isCall          = Is[*ast.CallExpr]()
+		isExpr          = Is[ast.Expr]()
+	)
+
+	if !isCall.Match(call) || isCall.Match(stmt) || isCall.Match(nil) {
+		t.Fatal("expected Is[*ast.CallExpr] to match call expressions only")
+	}
+
+	if !isExpr.Match(call) || isExpr.Match(stmt) || isExpr.Match(nil) {
+		t.Fatal("expected Is[ast.Expr] to match expressions only")
+	}
+
+	if Type(new(ast.CallExpr)).Match(nil) {
+		t.Fatal("expected Type not to match nil")
+	}
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"go/token"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestDiff_Filter(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	diff, err := ParseDiff(bytes.NewReader(MustReadFile(t, "diff.patch")))
+	FailOnError(t, err)
+
+	var (
+		data        = MustReadFile(t, "diff.go.txt")
+		fileSet     = token.NewFileSet()
+		f           = MustParse(t, fileSet, "pkg/diff.go", data)
+		diagnostics []string
+		edits       []TextEdit
+	)
+
+	FailOnError(t, Walk(f, rules.Matchers(fileSet, f, diff.Filter(func(d Diagnostic) {
+		diagnostics = append(diagnostics, d.String())
+		edits = append(edits, d.Edits...)
+	}))))
+
+	want := []string{
+		"pkg/diff.go:9:2: error: use zerolog instead of logrus.Error (logrus-call)",
+		"pkg/diff.go:10:7: info: double c.d (double-operand)",
+	}
+
+	if !reflect.DeepEqual(want, diagnostics) {
+		t.Fatalf("unexpected diagnostics:\nwant: %v\ngot:  %v", want, diagnostics)
+	}
+
+	if len(edits) != 1 || edits[0].Pos.Line != 9 {
+		t.Fatalf("expected only the edit on the changed line, got: %v", edits)
+	}
+}
+
+func TestDiff_Changed(t *testing.T) {
+	diff, err := ParseDiff(bytes.NewReader(MustReadFile(t, "diff.patch")))
+	FailOnError(t, err)
+
+	for _, tc := range []struct {
+		root     string
+		file     string
+		from, to int
+		want     bool
+	}{
+		{file: "pkg/diff.go", from: 8, to: 8, want: true},
+		{file: "./pkg/diff.go", from: 10, to: 10, want: true},
+		{root: "/src/repo", file: "/src/repo/pkg/diff.go", from: 3, to: 9, want: true},
+		{root: "/src/repo", file: "/src/repo/other/pkg/diff.go", from: 8, to: 8, want: false},
+		{root: "/src/repo/pkg", file: "/src/repo/pkg/diff.go", from: 8, to: 8, want: false},
+		{root: "/src/repo/pkg", file: "/src/repo/other/pkg/diff.go", from: 8, to: 8, want: false},
+		{file: "diff.go", from: 5, to: 7, want: false},
+		{file: "pkg/diff.go", from: 11, to: 12, want: false},
+		{file: "other/diff.go", from: 8, to: 8, want: false},
+		{file: "pkg/removed.go", from: 1, to: 3, want: false},
+	} {
+		if tc.root != "" {
+			FailOnError(t, diff.SetRoot(tc.root))
+		}
+
+		if got := diff.Changed(tc.file, tc.from, tc.to); got != tc.want {
+			t.Errorf("%v %v:%v-%v: expected %v, got %v", tc.root, tc.file, tc.from, tc.to, tc.want, got)
+		}
+	}
+}
+
+func TestParseDiff_invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"hunk header": "+++ b/a.go\n@@ -1 +x @@\n",
+		"hunk line":   "+++ b/a.go\n@@ -1,2 +1,2 @@\n a\n?b\n",
+	} {
+		if _, err := ParseDiff(strings.NewReader(data)); !errors.Is(err, ErrInvalidDiff) {
+			t.Errorf("%v: expected ErrInvalidDiff, got: %v", name, err)
+		}
+	}
+}
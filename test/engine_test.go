@@ -0,0 +1,127 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestEngine_RunFiles(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		dir   = t.TempDir()
+		files []string
+	)
+
+	for i := 0; i < 40; i++ {
+		var file = filepath.Join(dir, fmt.Sprintf("f%02d.go", i))
+
+		FailOnError(t, os.WriteFile(file, MustReadFile(t, "rules.go.txt"), 0600))
+
+		files = append(files, file)
+	}
+
+	var want = results(t, NewEngine(rules.Matchers, Workers(1)).RunFiles(files))
+	if len(want) != 40*4 {
+		t.Fatalf("expected 4 diagnostics per file, got %v", len(want))
+	}
+
+	for i := 0; i < 5; i++ {
+		if got := results(t, NewEngine(rules.Matchers, Workers(8)).RunFiles(files)); !reflect.DeepEqual(want, got) {
+			t.Fatalf("expected results in file order:\nwant: %v\ngot:  %v", want, got)
+		}
+	}
+}
+
+func results(t *testing.T, results []FileResult) []string {
+	var diagnostics []string
+
+	for _, r := range results {
+		FailOnError(t, r.Err)
+
+		for _, d := range r.Diagnostics {
+			diagnostics = append(diagnostics, d.String())
+		}
+	}
+
+	return diagnostics
+}
+
+func TestEngine_Run_suppressions(t *testing.T) {
+	rules, err := ParseRules([]byte("rules: [{id: no-panic, pattern: 'panic($_)', message: do not panic}]"))
+	FailOnError(t, err)
+
+	var (
+		fileSet = token.NewFileSet()
+		files   = []*ast.File{
+			MustParse(t, fileSet, "suppress.go", MustReadFile(t, "suppress.go.txt")),
+			MustParse(t, fileSet, "rules.go", MustReadFile(t, "rules.go.txt")),
+		}
+		engine = NewEngine(rules.Matchers, WithSuppressions())
+		got    []string
+	)
+
+	for _, r := range engine.Run(fileSet, files) {
+		FailOnError(t, r.Err)
+
+		for _, d := range r.Diagnostics {
+			got = append(got, d.String())
+		}
+	}
+
+	want := []string{
+		"suppress.go:18:2: info: do not panic (no-panic)",
+		"suppress.go:22:2: warning: unused suppression of no-panic (unused-suppression)",
+		"suppress.go:31:2: info: do not panic (no-panic)",
+		"suppress.go:31:13: warning: invalid suppression, separate the reason from the rules by -- (unused-suppression)",
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected diagnostics:\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+func TestEngine_RunFiles_error(t *testing.T) {
+	var engine = NewEngine(func(*token.FileSet, *ast.File, ReportFunc) PatternMatchers { return nil })
+
+	r := engine.RunFiles([]string{filepath.Join(t.TempDir(), "missing.go")})
+	if len(r) != 1 || !errors.Is(r[0].Err, fs.ErrNotExist) {
+		t.Fatalf("expected the missing file to fail, got: %+v", r)
+	}
+}
+
+func TestEngine_sharedVerifier(t *testing.T) {
+	var (
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "verify.go", MustReadFile(t, "verify.go.txt"))
+		none    = func(*token.FileSet, *ast.File, ReportFunc) PatternMatchers { return nil }
+		shared  = NewEngine(none, WithWalkOptions(Verify(NewVerifier(fileSet, []*ast.File{f}, nil))))
+	)
+
+	if r := shared.Run(fileSet, []*ast.File{f}); !errors.Is(r[0].Err, ErrSharedVerifier) {
+		t.Fatalf("expected ErrSharedVerifier, got: %v", r[0].Err)
+	}
+
+	var (
+		created int
+		perFile = NewEngine(none, WithWalkOptionFactory(func(fileSet *token.FileSet, file *ast.File) []WalkOption {
+			created++
+
+			return []WalkOption{Verify(NewVerifier(fileSet, []*ast.File{file}, nil))}
+		}))
+	)
+
+	FailOnError(t, perFile.Run(fileSet, []*ast.File{f})[0].Err)
+	AssertEquals(t, "1", fmt.Sprint(created))
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"errors"
+	"go/token"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+var errUnsupported = errors.New("unsupported call")
+
+func TestWalk_collectsMatchErrors(t *testing.T) {
+	var (
+		data    = MustReadFile(t, "errors.go.txt")
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "errors.go", data)
+	)
+
+	err := Walk(f, newUnsupportedCallMatchers(), WithFileSet(fileSet))
+
+	var matchErrs MatchErrors
+	if !errors.As(err, &matchErrs) {
+		t.Fatalf("expected MatchErrors, got: %v", err)
+	}
+
+	if len(matchErrs) != 2 {
+		t.Fatalf("expected 2 errors, got %v: %v", len(matchErrs), err)
+	}
+
+	for i, wantLine := range []int{5, 6} {
+		if matchErrs[i].Position.Line != wantLine {
+			t.Fatalf("expected error at line %v, got %v", wantLine, matchErrs[i].Position)
+		}
+	}
+
+	if !errors.Is(matchErrs[0], errUnsupported) {
+		t.Fatalf("expected error to wrap errUnsupported, got: %v", err)
+	}
+}
+
+func TestWalk_stopOnError(t *testing.T) {
+	var (
+		data    = MustReadFile(t, "errors.go.txt")
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "errors.go", data)
+	)
+
+	err := Walk(f, newUnsupportedCallMatchers(), WithFileSet(fileSet), StopOnError())
+
+	var matchErrs MatchErrors
+	if !errors.As(err, &matchErrs) {
+		t.Fatalf("expected MatchErrors, got: %v", err)
+	}
+
+	if len(matchErrs) != 1 {
+		t.Fatalf("expected 1 error, got %v: %v", len(matchErrs), err)
+	}
+}
+
+func TestWalk_stopOnErrorSkipsRemainingMatchers(t *testing.T) {
+	var (
+		data    = MustReadFile(t, "errors.go.txt")
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "errors.go", data)
+		calls   int
+	)
+
+	matchers := append(newUnsupportedCallMatchers(), New(
+		[]NodeCondition{CallExpr(IgnoreNode(), IgnoreNodes())},
+		func() error {
+			calls++
+
+			return nil
+		},
+	))
+
+	err := Walk(f, matchers, WithFileSet(fileSet), StopOnError())
+
+	var matchErrs MatchErrors
+	if !errors.As(err, &matchErrs) || len(matchErrs) != 1 {
+		t.Fatalf("expected 1 error, got: %v", err)
+	}
+
+	// the second matcher must not see the failing unsupported(1) call.
+	if calls != 1 {
+		t.Fatalf("expected 1 call before the first error, got %v", calls)
+	}
+}
+
+func newUnsupportedCallMatchers() PatternMatchers {
+	s1 := NodeSelections{}
+
+	return PatternMatchers{
+		New(
+			[]NodeCondition{
+				CallExpr(s1.Select(IgnoreNode(), "fun"), IgnoreNodes()),
+			},
+			func() error {
+				if s1.Ident("fun").Name == "unsupported" {
+					return errUnsupported
+				}
+
+				return nil
+			},
+		),
+	}
+}
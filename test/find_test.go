@@ -0,0 +1,180 @@
+package test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+const findSrc = `package p
+
+func f() {
+	a()
+	func() {
+		b()
+	}()
+	c()
+}
+`
+
+func callNames(t *testing.T, opts []WalkOption, processMatch func(call *ast.CallExpr) error) []string {
+	t.Helper()
+
+	var (
+		f     = MustParse(t, token.NewFileSet(), "", []byte(findSrc))
+		s1    = NodeSelections{}
+		names []string
+	)
+
+	err := Walk(f, PatternMatchers{
+		New([]NodeCondition{s1.Select(CallExpr(Type(new(ast.Ident)), IgnoreNodes()), "call")}, func() error {
+			var call = s1.CallExpr("call")
+
+			names = append(names, call.Fun.(*ast.Ident).Name)
+
+			return processMatch(call)
+		}),
+	}, opts...)
+	FailOnError(t, err)
+
+	return names
+}
+
+func TestWalk_stop(t *testing.T) {
+	var names = callNames(t, nil, func(call *ast.CallExpr) error {
+		if call.Fun.(*ast.Ident).Name == "b" {
+			return Stop
+		}
+
+		return nil
+	})
+
+	AssertEquals(t, "[a b]", fmt.Sprint(names))
+}
+
+func TestWalk_stopSkipsRemainingMatchers(t *testing.T) {
+	var (
+		f     = MustParse(t, token.NewFileSet(), "", []byte(findSrc))
+		calls int
+		match = func() error {
+			calls++
+
+			return Stop
+		}
+		cond = CallExpr(IgnoreNode(), IgnoreNodes())
+	)
+
+	err := Walk(f, PatternMatchers{New([]NodeCondition{cond}, match), New([]NodeCondition{cond}, match)})
+	FailOnError(t, err)
+
+	AssertEquals(t, "1", fmt.Sprint(calls))
+}
+
+func TestWalk_skipChildren(t *testing.T) {
+	var (
+		f     = MustParse(t, token.NewFileSet(), "", []byte(findSrc))
+		names []string
+	)
+
+	err := Walk(f, PatternMatchers{
+		New([]NodeCondition{Type(new(ast.FuncLit))}, func() error {
+			return SkipChildren
+		}),
+		New([]NodeCondition{Type(new(ast.Ident))}, func() error {
+			return nil
+		}),
+	}, Intercept(func(pm *Matcher, node ast.Node, _ NodeSelections, process func() error) error {
+		if ident, ok := node.(*ast.Ident); ok {
+			names = append(names, ident.Name)
+		}
+
+		return process()
+	}))
+	FailOnError(t, err)
+
+	AssertEquals(t, "[p f a c]", fmt.Sprint(names))
+}
+
+func TestWalk_skipIf(t *testing.T) {
+	var names = callNames(t, []WalkOption{SkipIf(Type(new(ast.FuncLit)))}, func(*ast.CallExpr) error {
+		return nil
+	})
+
+	AssertEquals(t, "[a c]", fmt.Sprint(names))
+}
+
+func TestWalk_controlErrorsDoNotRollBack(t *testing.T) {
+	var (
+		f  = MustParse(t, token.NewFileSet(), "", []byte(findSrc))
+		s1 = NodeSelections{}
+	)
+
+	err := Walk(f, PatternMatchers{
+		New([]NodeCondition{s1.Select(CallExpr(Type(new(ast.Ident)), IgnoreNodes()), "call")}, func() error {
+			s1.CallExpr("call").Fun.(*ast.Ident).Name = "x"
+
+			return Stop
+		}),
+	}, Transactional())
+	FailOnError(t, err)
+
+	AssertEquals(t, "x", f.Decls[0].(*ast.FuncDecl).Body.List[0].(*ast.ExprStmt).X.(*ast.CallExpr).Fun.(*ast.Ident).Name)
+}
+
+func TestFindFirst(t *testing.T) {
+	var (
+		fileSet   = token.NewFileSet()
+		f         = MustParse(t, fileSet, "find.go", []byte(findSrc))
+		processed bool
+		pms       = PatternMatchers{
+			New([]NodeCondition{CallExpr(Type(new(ast.Ident)), IgnoreNodes())}, func() error {
+				processed = true
+
+				return nil
+			}).Named("call"),
+		}
+	)
+
+	m, err := FindFirst(f, pms, WithFileSet(fileSet))
+	FailOnError(t, err)
+
+	if m == nil {
+		t.Fatal("expected a match")
+	}
+
+	AssertEquals(t, "call", m.Rule)
+	AssertEquals(t, "find.go:4:2", m.Position.String())
+
+	if processed {
+		t.Fatal("expected FindFirst not to process the match")
+	}
+
+	m, err = FindFirst(f, PatternMatchers{New([]NodeCondition{Type(new(ast.GoStmt))}, nil)})
+	FailOnError(t, err)
+
+	if m != nil {
+		t.Fatalf("expected no match, got %v", m)
+	}
+}
+
+func TestFindN(t *testing.T) {
+	var (
+		f   = MustParse(t, token.NewFileSet(), "", []byte(findSrc))
+		pms = PatternMatchers{New([]NodeCondition{CallExpr(Type(new(ast.Ident)), IgnoreNodes())}, nil)}
+	)
+
+	for n, want := range map[int]string{0: "[]", 2: "[a b]", 5: "[a b c]", -1: "[a b c]"} {
+		matches, err := FindN(f, pms, n)
+		FailOnError(t, err)
+
+		var names []string
+		for _, m := range matches {
+			names = append(names, m.Node.(*ast.CallExpr).Fun.(*ast.Ident).Name)
+		}
+
+		AssertEquals(t, want, fmt.Sprint(names))
+	}
+}
@@ -0,0 +1,13 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Oppodelldog/asterisk/asterisktest"
+	"github.com/Oppodelldog/asterisk/formatting"
+)
+
+func TestFormattingRules(t *testing.T) {
+	asterisktest.Run(t, filepath.Join("resources", "testdata", "formatting"), formatting.Rules())
+}
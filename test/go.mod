@@ -1,10 +1,17 @@
 module github.com/Oppodelldog/asterisk/test
 
-go 1.14
+go 1.23.0
 
 require (
 	github.com/Oppodelldog/asterisk v0.0.0
 	github.com/sergi/go-diff v1.1.0
+	golang.org/x/tools v0.33.0
+)
+
+require (
+	golang.org/x/mod v0.24.0 // indirect
+	golang.org/x/sync v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace github.com/Oppodelldog/asterisk v0.0.0 => ../
@@ -2,6 +2,7 @@ package test
 
 import (
 	"bytes"
+	"go/ast"
 	"go/printer"
 	"go/token"
 	"testing"
@@ -17,7 +18,7 @@ func TestIfStmt(t *testing.T) {
 		s1      = NodeSelections{}
 	)
 
-	Walk(f, PatternMatchers{
+	FailOnError(t, Walk(f, PatternMatchers{
 		New(
 			[]NodeCondition{
 				FuncDecl(
@@ -50,14 +51,16 @@ func TestIfStmt(t *testing.T) {
 					),
 				),
 			},
-			func() {
+			func() error {
 				var elseStmts = s1.BlockStmt("else").List
-				var ret = elseStmts[len(elseStmts)-1]
+				var ret = Clone(elseStmts[len(elseStmts)-1]).(ast.Stmt)
 				s1.BlockStmt("block").List = append(s1.BlockStmt("block").List, ret)
 				s1.IfStmt("if").Else = nil
+
+				return nil
 			},
 		),
-	})
+	}))
 
 	patched := bytes.NewBuffer([]byte{})
 	FailOnError(t, printer.Fprint(patched, fileSet, f))
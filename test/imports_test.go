@@ -18,7 +18,7 @@ func TestImports(t *testing.T) {
 		s1       = NodeSelections{}
 	)
 
-	Walk(f, PatternMatchers{
+	FailOnError(t, Walk(f, PatternMatchers{
 		New(
 			[]NodeCondition{
 				File(
@@ -31,12 +31,14 @@ func TestImports(t *testing.T) {
 					IgnoreNodes(),
 				),
 			},
-			func() {
+			func() error {
 				s1.ImportSpecs("imports")[0].Name.Name = "changedImportName"
 				s1.ImportSpecs("imports")[0].Path.Value = `"fmt"`
+
+				return nil
 			},
 		),
-	})
+	}))
 
 	patched := bytes.NewBuffer([]byte{})
 	FailOnError(t, printer.Fprint(patched, fileSet, f))
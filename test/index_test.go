@@ -0,0 +1,179 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+	"github.com/Oppodelldog/asterisk/index"
+)
+
+func TestIndex_NewRecord(t *testing.T) {
+	record, err := index.NewRecord("rules.go", MustReadFile(t, "rules.go.txt"))
+	FailOnError(t, err)
+
+	AssertEquals(t, "[AssignStmt BasicLit BinaryExpr BlockStmt CallExpr ExprStmt FieldList File FuncDecl FuncType Ident SelectorExpr]",
+		fmt.Sprint(record.Kinds))
+	AssertEquals(t, "[Got Want a log logrus msg resources y zerolog]", fmt.Sprint(record.Idents))
+	AssertEquals(t, "[DebugLevel Error Info Msg SetGlobalLevel SetLevel b]", fmt.Sprint(record.Selectors))
+	AssertEquals(t, `["Error hahaha"]`, fmt.Sprint(record.Literals))
+}
+
+func TestIndex_PatternRecord(t *testing.T) {
+	p, err := ParseCodePattern(`logrus.$method($msg, "text")`)
+	FailOnError(t, err)
+
+	var want = &index.Record{
+		Kinds:     []string{"BasicLit", "CallExpr", "Ident", "SelectorExpr"},
+		Idents:    []string{"logrus"},
+		Selectors: []string{},
+		Literals:  []string{`"text"`},
+	}
+
+	if got := index.PatternRecord(p); !reflect.DeepEqual(want, got) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestIndex_Update(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		a         = filepath.Join(dir, "a.go")
+		b         = filepath.Join(dir, "b.go")
+		c         = filepath.Join(dir, "c.go")
+		indexFile = filepath.Join(dir, "index.json")
+		idx       = index.New()
+	)
+
+	FailOnError(t, os.WriteFile(a, MustReadFile(t, "rules.go.txt"), 0600))
+	FailOnError(t, os.WriteFile(b, MustReadFile(t, "if.go.txt"), 0600))
+	FailOnError(t, os.WriteFile(c, MustReadFile(t, "if.go.txt"), 0600))
+
+	stats, err := idx.Update([]string{a, b, c})
+	FailOnError(t, err)
+	AssertEquals(t, "3 indexed, 0 unchanged, 0 removed", stats.String())
+	AssertEquals(t, "2", fmt.Sprint(len(idx.Records)))
+	FailOnError(t, idx.Save(indexFile))
+
+	idx, err = index.Load(indexFile)
+	FailOnError(t, err)
+
+	FailOnError(t, os.WriteFile(b, MustReadFile(t, "return.go.txt"), 0600))
+	FailOnError(t, os.Remove(c))
+
+	stats, err = idx.Update([]string{a, b})
+	FailOnError(t, err)
+	AssertEquals(t, "1 indexed, 1 unchanged, 1 removed", stats.String())
+	AssertEquals(t, "2", fmt.Sprint(len(idx.Records)))
+
+	stats, err = idx.Update([]string{a})
+	FailOnError(t, err)
+	AssertEquals(t, "0 indexed, 1 unchanged, 0 removed", stats.String())
+	AssertEquals(t, "2", fmt.Sprint(len(idx.Files)))
+}
+
+func TestIndex_Load_version(t *testing.T) {
+	var file = filepath.Join(t.TempDir(), "index.json")
+
+	FailOnError(t, os.WriteFile(file, []byte(`{"version": 99}`), 0600))
+
+	if _, err := index.Load(file); !errors.Is(err, index.ErrVersion) {
+		t.Fatalf("expected ErrVersion, got %v", err)
+	}
+}
+
+func TestIndex_Candidates(t *testing.T) {
+	rules, err := LoadRules(filepath.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		dir = t.TempDir()
+		idx = index.New()
+	)
+
+	files, err := filepath.Glob(filepath.Join("resources", "*.go.txt"))
+	FailOnError(t, err)
+
+	for i, file := range files {
+		files[i] = filepath.Join(dir, filepath.Base(file)+".go")
+		FailOnError(t, os.WriteFile(files[i], MustReadFile(t, filepath.Base(file)), 0600))
+	}
+
+	_, err = idx.Update(files)
+	FailOnError(t, err)
+
+	q, err := index.RulesQuery(rules)
+	FailOnError(t, err)
+
+	candidates, err := idx.Candidates(files, q)
+	FailOnError(t, err)
+
+	if len(candidates) == 0 || len(candidates) == len(files) {
+		t.Fatalf("expected the index to skip some files, got %v of %v", len(candidates), len(files))
+	}
+
+	for _, r := range NewEngine(rules.Matchers, WithSuppressions()).RunFiles(files) {
+		if r.Err == nil && len(r.Diagnostics) > 0 && !contains(candidates, r.Name) {
+			t.Errorf("expected %v to be a candidate, it has %v diagnostics", r.Name, len(r.Diagnostics))
+		}
+	}
+
+	var changed = files[0]
+	if contains(candidates, changed) {
+		changed = files[len(files)-1]
+	}
+
+	FailOnError(t, os.WriteFile(changed, []byte("package p\n\nvar x = 1\n"), 0600))
+
+	if candidates, err = idx.Candidates(files, q); err != nil || !contains(candidates, changed) {
+		t.Fatalf("expected the changed file %v to be a candidate, got %v: %v", changed, candidates, err)
+	}
+
+	FailOnError(t, os.Remove(changed))
+
+	if _, err = idx.Candidates(files, q); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected an error for the removed file, got %v", err)
+	}
+}
+
+func TestIndex_Candidates_suppressions(t *testing.T) {
+	rules, err := LoadRules(filepath.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		file = filepath.Join(t.TempDir(), "suppressed.go")
+		idx  = index.New()
+	)
+
+	FailOnError(t, os.WriteFile(file, []byte("package p\n\n//asterisk:ignore * -- unused\nvar x = 1\n"), 0600))
+
+	_, err = idx.Update([]string{file})
+	FailOnError(t, err)
+
+	q, err := index.RulesQuery(rules)
+	FailOnError(t, err)
+
+	candidates, err := idx.Candidates([]string{file}, q)
+	FailOnError(t, err)
+
+	AssertEquals(t, fmt.Sprint([]string{file}), fmt.Sprint(candidates))
+
+	for _, r := range NewEngine(rules.Matchers, WithSuppressions()).RunFiles(candidates) {
+		FailOnError(t, r.Err)
+		AssertEquals(t, "1", fmt.Sprint(len(r.Diagnostics)))
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
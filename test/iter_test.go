@@ -0,0 +1,123 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+var callPattern = NewPattern("call", []NodeCondition{CallExpr(Type(new(ast.Ident)), IgnoreNodes())}, nil)
+
+func TestAll(t *testing.T) {
+	var (
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "find.go", []byte(findSrc))
+		names   []string
+	)
+
+	for m, err := range All(f, callPattern, WithFileSet(fileSet)) {
+		FailOnError(t, err)
+
+		names = append(names, fmt.Sprintf("%v %v", m.Node.(*ast.CallExpr).Fun.(*ast.Ident).Name, m.Position))
+	}
+
+	AssertEquals(t, "[a find.go:4:2 b find.go:6:3 c find.go:8:2]", fmt.Sprint(names))
+}
+
+func TestAll_break(t *testing.T) {
+	var (
+		f     = MustParse(t, token.NewFileSet(), "", []byte(findSrc))
+		names []string
+	)
+
+	for m, err := range All(f, callPattern) {
+		FailOnError(t, err)
+
+		names = append(names, m.Node.(*ast.CallExpr).Fun.(*ast.Ident).Name)
+
+		if len(names) == 2 {
+			break
+		}
+	}
+
+	AssertEquals(t, "[a b]", fmt.Sprint(names))
+}
+
+func TestAll_canceled(t *testing.T) {
+	var (
+		f           = MustParse(t, token.NewFileSet(), "", []byte(findSrc))
+		ctx, cancel = context.WithCancel(context.Background())
+		errs        []error
+	)
+
+	cancel()
+
+	for _, err := range All(f, callPattern, WithContext(ctx)) {
+		errs = append(errs, err)
+	}
+
+	AssertEquals(t, fmt.Sprint([]error{context.Canceled}), fmt.Sprint(errs))
+
+	_, err := FindN(f, PatternMatchers{callPattern.Matcher()}, -1, WithContext(ctx))
+	if err != context.Canceled {
+		t.Fatalf("expected FindN to return the error of the walk, got %v", err)
+	}
+}
+
+func TestFindN_severalMatchesOnStoppingNode(t *testing.T) {
+	var (
+		f   = MustParse(t, token.NewFileSet(), "", []byte(findSrc))
+		pms = PatternMatchers{callPattern.Matcher(), callPattern.Matcher()}
+	)
+
+	matches, err := FindN(f, pms, 1)
+	FailOnError(t, err)
+
+	AssertEquals(t, "1", fmt.Sprint(len(matches)))
+}
+
+func TestStream(t *testing.T) {
+	var (
+		fileSet = token.NewFileSet()
+		files   = []*ast.File{
+			MustParse(t, fileSet, "f1.go", []byte(findSrc)),
+			MustParse(t, fileSet, "f2.go", []byte("package p\n\nvar x = d()\n")),
+		}
+		positions []string
+	)
+
+	for m := range Stream(context.Background(), fileSet, files, callPattern) {
+		positions = append(positions, m.Position.String())
+	}
+
+	AssertEquals(t, "[f1.go:4:2 f1.go:6:3 f1.go:8:2 f2.go:3:9]", fmt.Sprint(positions))
+}
+
+func TestStream_cancel(t *testing.T) {
+	var (
+		fileSet     = token.NewFileSet()
+		files       = []*ast.File{generatedFile(t, 100)}
+		ctx, cancel = context.WithCancel(context.Background())
+		pattern     = NewPattern("call", []NodeCondition{CallExpr(IgnoreNode(), IgnoreNodes())}, nil)
+		matches     = Stream(ctx, fileSet, files, pattern)
+	)
+
+	if _, ok := <-matches; !ok {
+		t.Fatal("expected a match")
+	}
+
+	cancel()
+
+	var n int
+	for range matches {
+		n++
+	}
+
+	if n > 1 {
+		t.Fatalf("expected the stream to end after cancel, got %v more matches", n)
+	}
+}
@@ -0,0 +1,195 @@
+package test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestKindsOf(t *testing.T) {
+	pattern, err := ParseCodePattern("$x + $x")
+	FailOnError(t, err)
+
+	var (
+		s        = NodeSelections{}
+		call     = CallExpr(IgnoreNode(), IgnoreNodes())
+		ifStmt   = IfStmt(IgnoreNode(), IgnoreNode(), IgnoreNode(), IgnoreNode())
+		callOrIf = Func(func(n ast.Node) bool {
+			return call.Match(n) || ifStmt.Match(n)
+		})
+	)
+
+	for name, tc := range map[string]struct {
+		c    NodeCondition
+		want []reflect.Type
+	}{
+		"constructor": {c: BlockStmt(IgnoreNodes()), want: kinds(new(ast.BlockStmt))},
+		"selected":    {c: s.Select(Ident("x"), "x"), want: kinds(new(ast.Ident))},
+		"perMatch":    {c: Select(Ident("x"), "x"), want: kinds(new(ast.Ident))},
+		"func":        {c: callOrIf},
+		"or":          {c: Or(call, ifStmt), want: kinds(new(ast.CallExpr), new(ast.IfStmt))},
+		"orAny":       {c: Or(call, IgnoreNode())},
+		"and":         {c: And(IgnoreNode(), call, Or(call, ifStmt)), want: kinds(new(ast.CallExpr))},
+		"not":         {c: Not(Ident("x"))},
+		"custom":      {c: IgnoreNode()},
+		"declared":    {c: Kinds(IgnoreNode(), new(ast.ReturnStmt)), want: kinds(new(ast.ReturnStmt))},
+		"pattern":     {c: pattern.Condition(), want: kinds(new(ast.BinaryExpr))},
+		"is":          {c: Is[*ast.CallExpr](), want: kinds(new(ast.CallExpr))},
+		"isInterface": {c: Is[ast.Expr]()},
+	} {
+		if got := KindsOf(tc.c); !reflect.DeepEqual(tc.want, got) {
+			t.Errorf("%v: want %v, got %v", name, tc.want, got)
+		}
+	}
+
+	if len(s) != 0 {
+		t.Fatalf("expected KindsOf not to select nodes, got: %v", s)
+	}
+}
+
+func TestWalk_undeclaredConditionsSeeAllNodes(t *testing.T) {
+	var (
+		f        = MustParse(t, token.NewFileSet(), "", []byte("package p\n\nfunc f() {\n\tgo a()\n\tb()\n}\n"))
+		goOrCall = Func(func(n ast.Node) bool {
+			return GoStmt(IgnoreNode()).Match(n) || CallExpr(IgnoreNode(), IgnoreNodes()).Match(n)
+		})
+		hasCall = Func(func(n ast.Node) bool {
+			var found bool
+			ast.Inspect(n, func(n ast.Node) bool {
+				_, ok := n.(*ast.CallExpr)
+				found = found || ok
+
+				return !found
+			})
+
+			return found
+		})
+		direct, walked int
+	)
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n != nil && goOrCall.Match(n) {
+			direct++
+		}
+
+		return true
+	})
+
+	FailOnError(t, Walk(f, PatternMatchers{
+		New([]NodeCondition{goOrCall}, func() error {
+			walked++
+
+			return nil
+		}),
+		NewPattern("", []NodeCondition{hasCall}, func(NodeSelections) error { return nil }).Matcher(),
+	}))
+
+	AssertEquals(t, fmt.Sprint(direct), fmt.Sprint(walked))
+}
+
+func kinds(nodes ...ast.Node) []reflect.Type {
+	var types []reflect.Type
+	for _, n := range nodes {
+		types = append(types, reflect.TypeOf(n))
+	}
+
+	return types
+}
+
+func TestWalk_dispatchMatchesUndeclared(t *testing.T) {
+	var f = generatedFile(t, 50)
+
+	declared, countDeclared := generatedMatchers(100, false)
+	undeclared, countUndeclared := generatedMatchers(100, true)
+
+	FailOnError(t, Walk(f, declared))
+	FailOnError(t, Walk(f, undeclared))
+
+	if *countDeclared == 0 || *countDeclared != *countUndeclared {
+		t.Fatalf("expected equal number of matches, got %v and %v", *countDeclared, *countUndeclared)
+	}
+}
+
+func BenchmarkWalk(b *testing.B) {
+	var f = generatedFile(b, 2000)
+
+	for _, undeclared := range []bool{false, true} {
+		pms, _ := generatedMatchers(120, undeclared)
+
+		b.Run(fmt.Sprintf("undeclared=%v", undeclared), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := Walk(f, pms); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// generatedFile returns a file of the given number of functions calling functions of different packages.
+func generatedFile(tb testing.TB, funcs int) *ast.File {
+	var sb strings.Builder
+
+	sb.WriteString("package generated\n")
+
+	for i := 0; i < funcs; i++ {
+		fmt.Fprintf(&sb, `
+func F%[1]d(a, b int) int {
+	if a > b {
+		pkg%[2]d.Call(a, b)
+	}
+
+	for i := 0; i < a; i++ {
+		b += i * 2
+	}
+
+	return a + b
+}
+`, i, i%150)
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "generated.go", sb.String(), 0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return f
+}
+
+// generatedMatchers returns matchers for calls of pkg0 to pkgN, and a pointer to the number of matches.
+// If undeclared is set, the first conditions hide their kinds, so every node is passed to them.
+func generatedMatchers(n int, undeclared bool) (PatternMatchers, *int) {
+	var (
+		pms   PatternMatchers
+		count int
+	)
+
+	for i := 0; i < n; i++ {
+		var (
+			call = CallExpr(SelectorExpr(Ident(fmt.Sprintf("pkg%d", i)), Ident("Call")), IgnoreNodes())
+			c    = ExprStmt(call)
+		)
+
+		if undeclared {
+			c = Func(func(n ast.Node) bool {
+				e, ok := n.(*ast.ExprStmt)
+
+				return ok && call.Match(e.X)
+			})
+		}
+
+		pms = append(pms, New([]NodeCondition{c}, func() error {
+			count++
+
+			return nil
+		}))
+	}
+
+	return pms, &count
+}
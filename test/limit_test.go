@@ -0,0 +1,147 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func countingMatchers(matches *int, err error) PatternMatchers {
+	return PatternMatchers{
+		New([]NodeCondition{CallExpr(IgnoreNode(), IgnoreNodes())}, func() error {
+			*matches++
+
+			return err
+		}),
+	}
+}
+
+func TestWalk_withContext(t *testing.T) {
+	var (
+		f           = generatedFile(t, 10)
+		matches     int
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	cancel()
+
+	if err := Walk(f, countingMatchers(&matches, nil), WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the walk to be canceled, got %v", err)
+	}
+
+	AssertEquals(t, "0", fmt.Sprint(matches))
+}
+
+func TestWalk_maxMatches(t *testing.T) {
+	var (
+		f        = generatedFile(t, 10)
+		matches  int
+		limitErr *LimitError
+	)
+
+	err := Walk(f, countingMatchers(&matches, nil), MaxMatches(3))
+	if !errors.As(err, &limitErr) || limitErr.Limit != LimitMatches {
+		t.Fatalf("expected the matches limit to be exceeded, got %v", err)
+	}
+
+	AssertEquals(t, "3", fmt.Sprint(matches))
+	AssertEquals(t, "limit exceeded: matches, max 3", err.Error())
+
+	matches = 0
+	FailOnError(t, Walk(f, countingMatchers(&matches, nil), MaxMatches(10)))
+	AssertEquals(t, "10", fmt.Sprint(matches))
+}
+
+func TestWalk_maxMatchesKeepsMatchErrors(t *testing.T) {
+	var (
+		f         = generatedFile(t, 10)
+		matches   int
+		matchErrs MatchErrors
+	)
+
+	err := Walk(f, countingMatchers(&matches, errRewriteFailed), MaxMatches(2))
+	if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &matchErrs) || len(matchErrs) != 2 {
+		t.Fatalf("expected two match errors and the exceeded limit, got %v", err)
+	}
+}
+
+func TestWalk_timeout(t *testing.T) {
+	var (
+		f        = generatedFile(t, 500)
+		matches  int
+		limitErr *LimitError
+		pms      = PatternMatchers{
+			New([]NodeCondition{Type(new(ast.FuncDecl))}, func() error {
+				if matches++; matches == 1 {
+					time.Sleep(10 * time.Millisecond)
+				}
+
+				return nil
+			}),
+		}
+	)
+
+	err := Walk(f, pms, Timeout(time.Millisecond))
+	if !errors.As(err, &limitErr) || limitErr.Limit != LimitDuration {
+		t.Fatalf("expected the duration limit to be exceeded, got %v", err)
+	}
+
+	if matches == 500 {
+		t.Fatal("expected the walk to be aborted")
+	}
+}
+
+func TestEngine_limits(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		src      = MustReadFile(t, "rules.go.txt")
+		small    = filepath.Join(t.TempDir(), "small.go")
+		large    = filepath.Join(t.TempDir(), "large.go")
+		limitErr *LimitError
+		engine   = NewEngine(rules.Matchers, MaxFileSize(int64(len(src))), WithWalkOptions(MaxMatches(1)))
+	)
+
+	FailOnError(t, os.WriteFile(small, src, 0600))
+	FailOnError(t, os.WriteFile(large, append(src, "// padding\n"...), 0600))
+
+	var results = engine.RunFiles([]string{small, large})
+
+	if !errors.As(results[0].Err, &limitErr) || limitErr.Limit != LimitMatches || len(results[0].Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic and the matches limit to be exceeded, got %v: %v",
+			results[0].Diagnostics, results[0].Err)
+	}
+
+	if !errors.As(results[1].Err, &limitErr) || limitErr.Limit != LimitFileSize || results[1].Src != nil {
+		t.Fatalf("expected the large file to be skipped, got %v", results[1].Err)
+	}
+}
+
+func TestEngine_RunContext_canceled(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		fileSet     = token.NewFileSet()
+		files       = []*ast.File{MustParse(t, fileSet, "rules.go", MustReadFile(t, "rules.go.txt"))}
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	cancel()
+
+	for _, r := range NewEngine(rules.Matchers).RunContext(ctx, fileSet, files) {
+		if r.Name != "rules.go" || !errors.Is(r.Err, context.Canceled) {
+			t.Fatalf("expected the file to be canceled, got %v: %v", r.Name, r.Err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"go/ast"
+	"go/token"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestWalk_resetsStateAtFileBoundaries(t *testing.T) {
+	var (
+		fileSet = token.NewFileSet()
+		f1      = MustParse(t, fileSet, "f1.go", []byte("package p\n\nvar x = a\n"))
+		f2      = MustParse(t, fileSet, "f2.go", []byte("package p\n\nvar y = a\n"))
+		matches int
+		pms     = PatternMatchers{
+			New([]NodeCondition{Ident("a"), Type(new(ast.File))}, func() error {
+				matches++
+
+				return nil
+			}),
+		}
+	)
+
+	FailOnError(t, Walk(f1, pms))
+	FailOnError(t, Walk(f2, pms))
+	FailOnError(t, Walk(&ast.Package{Name: "p", Files: map[string]*ast.File{"f1.go": f1, "f2.go": f2}}, pms))
+
+	if matches != 0 {
+		t.Fatalf("expected a match not to continue in the next file, got %v matches", matches)
+	}
+}
+
+func TestPattern_concurrentWalks(t *testing.T) {
+	var (
+		matches int64
+		pattern = NewPattern("call", []NodeCondition{
+			ExprStmt(CallExpr(SelectorExpr(Ident("pkg1"), Ident("Call")), IgnoreNodes())),
+		}, func(NodeSelections) error {
+			atomic.AddInt64(&matches, 1)
+
+			return nil
+		})
+		pms = PatternMatchers{pattern.Matcher()}
+		wg  sync.WaitGroup
+	)
+
+	for i := 0; i < 8; i++ {
+		var f = generatedFile(t, 300)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := Walk(f, pms); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if pattern.Name() != "call" || matches != 8*2 {
+		t.Fatalf("expected 2 matches per walk, got %v", matches)
+	}
+}
+
+func TestPattern_concurrentWalksSelect(t *testing.T) {
+	code, err := ParseCodePattern("$pkg.Call($a, $b)")
+	FailOnError(t, err)
+
+	var (
+		matches int64
+		pattern = NewPattern("call", []NodeCondition{Select(Is[*ast.ExprStmt](), "stmt"), code.Condition()},
+			func(s NodeSelections) error {
+				var call = s.ExprStmt("stmt").X.(*ast.CallExpr)
+
+				if s.Expr("a") != call.Args[0] || s.Expr("b") != call.Args[1] {
+					t.Errorf("expected the selections of the match, got %v and %v", s.Expr("a"), s.Expr("b"))
+				}
+
+				atomic.AddInt64(&matches, 1)
+
+				return nil
+			})
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < 8; i++ {
+		var f = generatedFile(t, 300)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := Walk(f, PatternMatchers{pattern.Matcher()}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if matches != 8*300 {
+		t.Fatalf("expected 300 matches per walk, got %v", matches)
+	}
+}
+
+func TestPattern_withoutConditions(t *testing.T) {
+	var (
+		f       = MustParse(t, token.NewFileSet(), "", []byte(findSrc))
+		pattern = NewPattern("empty", nil, func(NodeSelections) error {
+			t.Fatal("expected a pattern without conditions not to match")
+
+			return nil
+		})
+	)
+
+	FailOnError(t, Walk(f, PatternMatchers{pattern.Matcher(), New([]NodeCondition{Is[*ast.FuncDecl](), Is[*ast.Ident]()}, func() error { return nil })}))
+	FailOnError(t, pattern.Matcher().Match(f))
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestPositions(t *testing.T) {
+	var (
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "position.go", MustReadFile(t, "position.go.txt"))
+		p       = NewPositions(fileSet, f)
+		got     = map[string][]int{}
+	)
+
+	for name, c := range map[string]NodeCondition{
+		"StartsLine":   p.StartsLine(),
+		"OnSameLine":   p.OnSameLine(IgnoreNode()),
+		"Last":         p.Last(),
+		"Index>0":      p.Index(AtLeast(1)),
+		"BlankAfter=0": p.BlankLinesAfter(Exactly(0)),
+	} {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if _, ok := n.(ast.Stmt); ok && c.Match(n) {
+				got[name] = append(got[name], fileSet.Position(n.Pos()).Line*100+fileSet.Position(n.Pos()).Column)
+			}
+
+			return true
+		})
+	}
+
+	want := map[string][]int{
+		"StartsLine":   {402, 503, 703, 902},
+		"OnSameLine":   {907},
+		"Last":         {503, 703, 907},
+		"Index>0":      {902, 907},
+		"BlankAfter=0": {402, 503, 703, 902, 907},
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected matches (line*100+column):\nwant: %v\ngot:  %v", want, got)
+	}
+}
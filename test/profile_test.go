@@ -0,0 +1,154 @@
+package test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strings"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func logrusErrorMatcher() *Matcher {
+	return New([]NodeCondition{
+		ExprStmt(CallExpr(SelectorExpr(Ident("logrus"), Ident("Error")), IgnoreNodes())),
+	}, func() error {
+		return nil
+	}).Named("logrus-error")
+}
+
+func TestProfiler_Stats(t *testing.T) {
+	var (
+		f   = MustParse(t, token.NewFileSet(), "", MustReadFile(t, "rules.go.txt"))
+		p   = NewProfiler()
+		pms = PatternMatchers{logrusErrorMatcher()}
+	)
+
+	FailOnError(t, Walk(f, pms, Profile(p)))
+
+	var got []string
+	for _, s := range p.Stats() {
+		got = append(got, fmt.Sprintf("%v %v %v/%v", s.Rule, s.Path, s.Matches, s.Calls))
+	}
+
+	AssertEquals(t, strings.Join([]string{
+		"logrus-error ExprStmt 1/6",
+		"logrus-error ExprStmt/CallExpr 1/6",
+		"logrus-error ExprStmt/CallExpr/SelectorExpr 1/6",
+		"logrus-error ExprStmt/CallExpr/SelectorExpr/Ident(Error) 1/4",
+		"logrus-error ExprStmt/CallExpr/SelectorExpr/Ident(logrus) 4/6",
+	}, "\n"), strings.Join(got, "\n"))
+
+	FailOnError(t, Walk(f, pms))
+
+	if s := p.Stats(); s[0].Calls != 6 {
+		t.Fatalf("expected walks without Profile not to record, got %v calls", s[0].Calls)
+	}
+
+	FailOnError(t, Walk(f, pms, Profile(p)))
+
+	if s := p.Stats(); s[0].Calls != 12 {
+		t.Fatalf("expected the stats of both profiled walks, got %v calls", s[0].Calls)
+	}
+}
+
+func TestProfiler_WriteReport(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		p      = NewProfiler()
+		sb     strings.Builder
+		files  = []string{path.Join("resources", "rules.go.txt"), path.Join("resources", "if.go.txt")}
+		engine = NewEngine(NewRuleTree(rules).Matchers, Workers(8), WithWalkOptions(Profile(p)))
+	)
+
+	results(t, engine.RunFiles(files))
+
+	FailOnError(t, p.WriteReport(&sb))
+
+	for _, want := range []string{"logrus-call", "Pattern(logrus.$method($msg))", "logrus-setlevel", "double-operand"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("expected the report to contain %q:\n%v", want, sb.String())
+		}
+	}
+}
+
+func TestProfiler_neverMatched(t *testing.T) {
+	var (
+		f  = MustParse(t, token.NewFileSet(), "", MustReadFile(t, "rules.go.txt"))
+		p  = NewProfiler()
+		sb strings.Builder
+		pm = New([]NodeCondition{CallExpr(Is[*ast.FuncLit](), IgnoreNodes())}, nil).Named("call-literal")
+	)
+
+	FailOnError(t, Walk(f, PatternMatchers{pm}, Profile(p)))
+
+	FailOnError(t, p.WriteReport(&sb))
+
+	if !strings.Contains(sb.String(), "never matched") {
+		t.Fatalf("expected the report to mark conditions that never matched:\n%v", sb.String())
+	}
+}
+
+func TestProfiler_ruleTree(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		p      = NewProfiler()
+		engine = NewEngine(NewRuleTree(rules).Matchers, WithWalkOptions(Profile(p)))
+		got    []string
+	)
+
+	results(t, engine.RunFiles([]string{path.Join("resources", "rules.go.txt")}))
+
+	for _, s := range p.Stats() {
+		got = append(got, fmt.Sprintf("%v %v %v/%v", s.Rule, s.Path, s.Matches, s.Calls))
+	}
+
+	AssertEquals(t, strings.Join([]string{
+		"(shared) DecisionTree 6/9",
+		"double-operand Pattern($x + $x) 2/2",
+		"logrus-call Pattern(logrus.$method($msg)) 4/7",
+		"logrus-setlevel Pattern(logrus.SetLevel($level)) 1/7",
+	}, "\n"), strings.Join(got, "\n"))
+}
+
+func TestProfiler_nodesConditions(t *testing.T) {
+	var (
+		f    = MustParse(t, token.NewFileSet(), "", []byte("package p\n\nfunc f() int {\n\tfoo(a)\n\tfoo(1)\n\n\treturn a\n}\n"))
+		p    = NewProfiler()
+		got  []string
+		none = func() error { return nil }
+		pms  = PatternMatchers{
+			New([]NodeCondition{BlockStmt(Last(ReturnStmt(Exprs([]NodeCondition{Ident("a")}))))}, none).Named("block"),
+			New([]NodeCondition{CallExpr(Ident("foo"), Exprs([]NodeCondition{Ident("a")}))}, none).Named("call"),
+			New([]NodeCondition{CallExpr(IdentExpr("foo"), Exprs([]NodeCondition{BasicLit("1")}))}, none).Named("literal"),
+		}
+	)
+
+	FailOnError(t, Walk(f, pms, Profile(p)))
+
+	for _, s := range p.Stats() {
+		got = append(got, fmt.Sprintf("%v %v %v/%v", s.Rule, s.Path, s.Matches, s.Calls))
+	}
+
+	AssertEquals(t, strings.Join([]string{
+		"block BlockStmt 1/1",
+		"block BlockStmt/Last 1/1",
+		"block BlockStmt/Last/ReturnStmt 1/1",
+		"block BlockStmt/Last/ReturnStmt/Exprs 1/1",
+		"block BlockStmt/Last/ReturnStmt/Exprs/Ident(a) 1/1",
+		"call CallExpr 1/2",
+		"call CallExpr/Exprs 1/2",
+		"call CallExpr/Exprs/Ident(a) 1/2",
+		"call CallExpr/Ident(foo) 2/2",
+		"literal CallExpr 1/2",
+		"literal CallExpr/Exprs 1/2",
+		"literal CallExpr/Exprs/BasicLit(1) 1/2",
+		"literal CallExpr/Ident(foo) 2/2",
+	}, "\n"), strings.Join(got, "\n"))
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+	"github.com/Oppodelldog/asterisk/formatting"
+)
+
+func TestRegistry_Select(t *testing.T) {
+	var registry = NewRegistry()
+
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+	FailOnError(t, registry.Register(rules...))
+	FailOnError(t, registry.Register(formatting.Rules()...))
+
+	for _, tc := range []struct {
+		name            string
+		enable, disable []string
+		want            []string
+	}{
+		{name: "all", want: []string{
+			"cuddled-declaration", "double-operand", "leading-blank-lines", "logrus-call",
+			"logrus-setlevel", "trailing-blank-lines", "whitespace-before-return",
+		}},
+		{name: "id", enable: []string{"double-operand"}, want: []string{"double-operand"}},
+		{name: "tag", enable: []string{"wsl", "logrus"}, want: []string{
+			"cuddled-declaration", "logrus-call", "whitespace-before-return",
+		}},
+		{name: "category", enable: []string{"logging"}, disable: []string{"logrus"}, want: []string{"logrus-setlevel"}},
+		{name: "disable", disable: []string{"formatting", "migration"}, want: []string{"double-operand"}},
+	} {
+		selected, err := registry.Select(tc.enable, tc.disable)
+		FailOnError(t, err)
+
+		var got []string
+		for _, r := range selected {
+			got = append(got, r.ID)
+		}
+
+		if !reflect.DeepEqual(tc.want, got) {
+			t.Errorf("%v: want %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	if _, err := registry.Select(nil, []string{"unknown"}); !errors.Is(err, ErrUnknownRule) {
+		t.Errorf("expected ErrUnknownRule, got: %v", err)
+	}
+
+	if err := registry.Register(rules[0]); !errors.Is(err, ErrDuplicateRule) {
+		t.Errorf("expected ErrDuplicateRule, got: %v", err)
+	}
+}
+
+func TestRegistry_metadata(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var registry = NewRegistry()
+	FailOnError(t, registry.Register(rules...))
+
+	r, ok := registry.Rule("logrus-call")
+	if !ok {
+		t.Fatal("expected rule logrus-call to be registered")
+	}
+
+	want := Examples{Good: []string{`log.Error().Msg("failed")`}, Bad: []string{`logrus.Error("failed")`}}
+
+	if r.Title != "Log with zerolog" || r.Category != "logging" || !reflect.DeepEqual(r.Tags, []string{"migration", "logrus"}) ||
+		!reflect.DeepEqual(r.Examples, want) || r.Severity != SeverityError {
+		t.Fatalf("unexpected metadata: %+v", r)
+	}
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+	"github.com/Oppodelldog/asterisk/report"
+)
+
+func TestReporters(t *testing.T) {
+	for format, file := range map[string]string{
+		"text":       "report.txt",
+		"jsonl":      "report.jsonl",
+		"sarif":      "report.sarif",
+		"checkstyle": "report.checkstyle.xml",
+		"junit":      "report.junit.xml",
+	} {
+		var buf bytes.Buffer
+
+		reporter, err := report.New(format, &buf)
+		FailOnError(t, err)
+
+		for _, d := range reportDiagnostics() {
+			FailOnError(t, reporter.Report(d))
+		}
+
+		FailOnError(t, reporter.Close())
+
+		AssertEquals(t, string(MustReadFile(t, file)), buf.String())
+	}
+}
+
+func TestReporters_unknownFormat(t *testing.T) {
+	if _, err := report.New("pdf", &bytes.Buffer{}); !errors.Is(err, report.ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got: %v", err)
+	}
+}
+
+func TestSARIF_columnsAndRules(t *testing.T) {
+	var (
+		file = filepath.Join(t.TempDir(), "a.go")
+		buf  bytes.Buffer
+	)
+
+	FailOnError(t, os.WriteFile(file, []byte("package a\n\nvar s = \"äö\" + x\n"), 0600))
+
+	reporter, err := report.New("sarif", &buf, report.WithRules(Rules{
+		{ID: "no-x", Title: "No x", Description: "x must not be used.", Category: "style", Tags: []string{"naming"}},
+	}))
+	FailOnError(t, err)
+
+	FailOnError(t, reporter.Report(Diagnostic{
+		Rule:    "no-x",
+		Message: "do not use x",
+		Pos:     token.Position{Filename: file, Line: 3, Column: 18},
+		End:     token.Position{Filename: file, Line: 3, Column: 19},
+	}))
+	FailOnError(t, reporter.Close())
+
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						Name             string
+						ShortDescription struct{ Text string }
+						FullDescription  struct{ Text string }
+						Properties       struct{ Tags []string }
+					}
+				}
+			}
+			ColumnKind string
+			Results    []struct {
+				Locations []struct {
+					PhysicalLocation struct {
+						Region struct{ StartColumn, EndColumn int }
+					}
+				}
+			}
+		}
+	}
+
+	FailOnError(t, json.Unmarshal(buf.Bytes(), &log))
+
+	var (
+		run    = log.Runs[0]
+		rule   = run.Tool.Driver.Rules[0]
+		region = run.Results[0].Locations[0].PhysicalLocation.Region
+	)
+
+	AssertEquals(t, "unicodeCodePoints", run.ColumnKind)
+	AssertEquals(t, "16-17", fmt.Sprintf("%v-%v", region.StartColumn, region.EndColumn))
+	AssertEquals(t, "No x|No x|x must not be used.|[naming]",
+		fmt.Sprintf("%v|%v|%v|%v", rule.Name, rule.ShortDescription.Text, rule.FullDescription.Text, rule.Properties.Tags))
+}
+
+func reportDiagnostics() []Diagnostic {
+	var pos = func(file string, line, column int) token.Position {
+		return token.Position{Filename: file, Line: line, Column: column}
+	}
+
+	return []Diagnostic{
+		{
+			Rule:     "logrus-call",
+			Severity: SeverityError,
+			Message:  "use zerolog instead of logrus.Error",
+			Pos:      pos("a.go", 5, 2),
+			End:      pos("a.go", 5, 30),
+			Edits: []TextEdit{
+				{Pos: pos("a.go", 5, 2), End: pos("a.go", 5, 30), NewText: `log.Error().Msg("Error")`},
+			},
+			Fingerprint: "3f2a9c1d5e7b8a604c1d2e3f4a5b6c7d",
+		},
+		{
+			Rule:     "empty-lines",
+			Severity: SeverityWarning,
+			Message:  "unnecessary empty line before return true",
+			Pos:      pos("b.go", 10, 3),
+			End:      pos("b.go", 10, 14),
+			Related:  []token.Position{pos("b.go", 8, 16)},
+		},
+	}
+}
@@ -0,0 +1,8 @@
+package fail
+
+import "github.com/sirupsen/logrus"
+
+func Log() {
+	logrus.Error("Error hahaha")
+	logrus.Info("Info") // want "use zerolog instead of logrus.Warn"
+}
@@ -0,0 +1,14 @@
+package pass
+
+import "github.com/sirupsen/logrus"
+
+func Log(msg string) {
+	logrus.SetLevel(logrus.DebugLevel) // want `zerolog.SetGlobalLevel instead of logrus.SetLevel\(logrus.DebugLevel\)`
+	logrus.Error("Error hahaha")       // want "use zerolog instead of logrus.Error"
+	logrus.Info(msg)
+	logrus.Warn("ignored") //asterisk:ignore logrus-call -- migrated later
+}
+
+func Sum(a struct{ b int }) int {
+	return a.b + a.b // want "double a.b"
+}
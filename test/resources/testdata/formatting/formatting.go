@@ -0,0 +1,52 @@
+package formatting
+
+func Leading() bool {
+
+	return true // want "unnecessary blank line before return true"
+}
+
+func Trailing() int {
+	x := 1
+
+	x++
+	return x // want "missing blank line before return x" "unnecessary blank line after return x"
+
+}
+
+func Return(a int) int {
+	a++
+	a--
+	// return a
+	return a // want "missing blank line before return a"
+}
+
+func ShortReturn(a int) int {
+	a++
+	return a
+}
+
+func Declaration() int {
+	a := 1
+	var b = 2 // want "missing blank line before var b = 2"
+
+	var c = 3
+	var d = 4 // want "missing blank line before var d = 4"
+	a++; var e = 5
+
+	return a + b + c + d + e
+}
+
+func Switch(a int) int {
+	switch a {
+	case 1:
+
+		a++ // want `unnecessary blank line before a\+\+`
+
+	case 2:
+		a--
+	}
+
+	if a > 0 { a++ }
+
+	return a
+}
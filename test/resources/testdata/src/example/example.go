@@ -0,0 +1,17 @@
+package example
+
+import "strings"
+
+func Example(s string) string {
+	if s == "" {
+		panic("empty") // want "do not panic"
+	}
+
+	return strings.ToUpper(s) // want "use strings.ToLower instead of strings.ToUpper"
+}
+
+func ExampleLines(s string) string {
+	return strings.ToUpper( // want "use strings.ToLower instead of strings.ToUpper"
+		s, // keep this comment
+	)
+}
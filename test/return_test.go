@@ -1,7 +1,9 @@
 package test
 
 import (
+	"go/ast"
 	"go/token"
+	"reflect"
 	"testing"
 
 	. "github.com/Oppodelldog/asterisk"
@@ -9,45 +11,87 @@ import (
 
 func TestReturnStmt_detectEmptyLines(t *testing.T) {
 	var (
-		data    = MustReadFile(t, "return.go.txt")
-		fileSet = token.NewFileSet()
-		f       = MustParse(t, fileSet, "", data)
-		s1      = NodeSelections{}
+		data        = MustReadFile(t, "return.go.txt")
+		fileSet     = token.NewFileSet()
+		f           = MustParse(t, fileSet, "return.go", data)
+		s1          = NodeSelections{}
+		diagnostics []Diagnostic
 	)
 
-	Walk(f, PatternMatchers{
+	before, err := ParseMessage("unnecessary empty line before {{.return}}")
+	FailOnError(t, err)
+
+	after, err := ParseMessage("unnecessary empty line after {{.return}}")
+	FailOnError(t, err)
+
+	FailOnError(t, Walk(f, PatternMatchers{
 		New(
 			[]NodeCondition{
 				s1.Select(
 					BlockStmt(
-						Last(ReturnStmt(IgnoreNodes())),
+						Last(s1.Select(ReturnStmt(IgnoreNodes()), "return")),
 					), "blockWithReturn",
 				),
 			},
-			func() {
+			func() error {
 				var (
 					block        = s1.BlockStmt("blockWithReturn")
 					stmts        = block.List
-					last         = stmts[0]
+					last         = stmts[len(stmts)-1]
 					lineAt       = fileSet.Position(last.End()).Line
 					lineEndBlock = fileSet.Position(block.Rbrace).Line
-					lineBefore   int
+					posBefore    = endBefore(block, stmts)
+					lineBefore   = fileSet.Position(posBefore).Line
 				)
 
-				if len(stmts) == 1 {
-					lineBefore = fileSet.Position(block.Lbrace).Line
-				} else {
-					beforeLast := stmts[len(stmts)-2]
-					lineBefore = fileSet.Position(beforeLast.End()).Line
+				report := func(message *Message, related token.Pos) error {
+					msg, err := message.Render(fileSet, s1)
+					if err != nil {
+						return err
+					}
+
+					d := NewDiagnostic(fileSet, last, "empty-lines", SeverityWarning, msg)
+					d.Related = []token.Position{fileSet.Position(related)}
+					diagnostics = append(diagnostics, d)
+
+					return nil
 				}
 
 				if lineAt-lineBefore > 1 {
-					t.Logf("unnecessary empty line before return (%v - %v)", lineAt, lineEndBlock)
+					if err := report(before, posBefore); err != nil {
+						return err
+					}
 				}
+
 				if lineEndBlock-lineAt > 1 {
-					t.Logf("unnecessary empty line before closing brace (%v - %v)", lineAt, lineEndBlock)
+					return report(after, block.Rbrace)
 				}
+
+				return nil
 			},
 		),
-	})
+	}))
+
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.String())
+	}
+
+	want := []string{
+		"return.go:10:3: warning: unnecessary empty line before return true (empty-lines)",
+		"return.go:15:3: warning: unnecessary empty line before return true (empty-lines)",
+		"return.go:15:3: warning: unnecessary empty line after return true (empty-lines)",
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected diagnostics:\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+func endBefore(block *ast.BlockStmt, stmts []ast.Stmt) token.Pos {
+	if len(stmts) == 1 {
+		return block.Lbrace
+	}
+
+	return stmts[len(stmts)-2].End()
 }
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"errors"
+	"go/token"
+	"path"
+	"reflect"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestRules(t *testing.T) {
+	var (
+		data        = MustReadFile(t, "rules.go.txt")
+		fileSet     = token.NewFileSet()
+		f           = MustParse(t, fileSet, "rules.go", data)
+		diagnostics []Diagnostic
+		edits       []TextEdit
+	)
+
+	rules, err := LoadRules(path.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	FailOnError(t, Walk(f, rules.Matchers(fileSet, f, func(d Diagnostic) {
+		diagnostics = append(diagnostics, d)
+		edits = append(edits, d.Edits...)
+	})))
+
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.String())
+	}
+
+	want := []string{
+		"rules.go:4:2: warning: use zerolog.SetGlobalLevel instead of logrus.SetLevel(logrus.DebugLevel) (logrus-setlevel)",
+		"rules.go:5:2: error: use zerolog instead of logrus.Error (logrus-call)",
+		"rules.go:7:7: info: double a.b (double-operand)",
+		"rules.go:14:7: info: double a.b (double-operand)",
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected diagnostics:\nwant: %v\ngot:  %v", want, got)
+	}
+
+	fixed, skipped, err := ApplyEdits(data, edits)
+	FailOnError(t, err)
+
+	if len(skipped) != 0 {
+		t.Fatalf("expected no edit to be skipped, got: %v", skipped)
+	}
+
+	AssertEquals(t, GetFunctionBody(t, data, "Want"), GetFunctionBody(t, fixed, "Got"))
+}
+
+func TestLoadRules_json(t *testing.T) {
+	rules, err := LoadRules(path.Join("resources", "rules.json"))
+	FailOnError(t, err)
+
+	if len(rules) != 1 || rules[0].ID != "no-panic" || rules[0].Severity != SeverityError {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+}
+
+func TestParseRules_invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"pattern":     "rules: [{id: a, pattern: 'func(', message: m}]",
+		"constraint":  "rules: [{id: a, pattern: 'f($x)', message: m, where: {y: {matches: '.'}}}]",
+		"duplicate":   "rules: [{id: a, pattern: 'f()', message: m}, {id: a, pattern: 'g()', message: m}]",
+		"replacement": "rules: [{id: a, pattern: 'f($x)', message: m, replacement: 'g($y)'}]",
+		"message":     "rules: [{id: a, pattern: 'panic($v)', message: 'do not panic {{.value}}'}]",
+		"kind":        "rules: [{id: a, pattern: 'f($x)', message: m, where: {x: {kind: BasicLiteral}}}]",
+	} {
+		if _, err := ParseRules([]byte(data)); !errors.Is(err, ErrInvalidRule) && !errors.Is(err, ErrDuplicateRule) {
+			t.Fatalf("%v: expected rule to be rejected, got: %v", name, err)
+		}
+	}
+}
+
+func TestCodePattern(t *testing.T) {
+	for src, want := range map[string]bool{
+		"f($x, $x)":          true,
+		"f($x, $y)":          true,
+		"f($x, 2)":           false,
+		"f($_, $_)":          true,
+		"if $c { $s }":       false,
+		"f(1, 1)":            true,
+		"f(1, 1, $rest)":     false,
+		"g := $x":            false,
+		"$fn($_, $_)":        true,
+		"f($x, $x) + $other": false,
+	} {
+		var (
+			data    = []byte("package p\n\nfunc F() {\n\tf(1, 1)\n}\n")
+			fileSet = token.NewFileSet()
+			f       = MustParse(t, fileSet, "", data)
+			matched bool
+		)
+
+		pattern, err := ParseCodePattern(src)
+		FailOnError(t, err)
+
+		FailOnError(t, Walk(f, PatternMatchers{
+			NewPattern("", []NodeCondition{pattern.Condition()}, func(s NodeSelections) error {
+				matched = true
+
+				for _, v := range pattern.Vars() {
+					if _, ok := s[v]; !ok {
+						t.Fatalf("%v: expected $%v to be captured", src, v)
+					}
+				}
+
+				return nil
+			}).Matcher(),
+		}))
+
+		if matched != want {
+			t.Fatalf("%v: expected match to be %v", src, want)
+		}
+	}
+}
+
+func TestRule_Compile_checkMessage(t *testing.T) {
+	var rule = &Rule{ID: "a", Check: &Check{Condition: func(*Positions) NodeCondition { return IgnoreNode() }}, Message: "{{.value}}"}
+	if err := rule.Compile(); !errors.Is(err, ErrInvalidRule) {
+		t.Fatalf("expected the message to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestStmtList_edits(t *testing.T) {
+	var (
+		data    = MustReadFile(t, "stmt.go.txt")
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "", data)
+		s1      = NodeSelections{}
+		s2      = NodeSelections{}
+		s3      = NodeSelections{}
+	)
+
+	FailOnError(t, Walk(f, PatternMatchers{
+		New(
+			[]NodeCondition{
+				s1.Select(
+					ExprStmt(
+						CallExpr(
+							Ident("must"),
+							Exprs([]NodeCondition{s1.Select(IgnoreNode(), "arg")}),
+						),
+					),
+					"stmt",
+				),
+			},
+			func() error {
+				var stmt = s1.Stmt("stmt")
+
+				return ReplaceStmt(StmtParent(f, stmt), stmt, createCheckErrStmt(s1.Expr("arg")))
+			},
+		),
+		New(
+			[]NodeCondition{
+				s2.Select(ExprStmt(CallExpr(Ident("debug"), IgnoreNodes())), "stmt"),
+			},
+			func() error {
+				var stmt = s2.Stmt("stmt")
+
+				return DeleteStmt(StmtParent(f, stmt), stmt)
+			},
+		),
+		New(
+			[]NodeCondition{
+				s3.Select(ExprStmt(CallExpr(Ident("handle"), IgnoreNodes())), "stmt"),
+			},
+			func() error {
+				var (
+					stmt   = s3.Stmt("stmt")
+					parent = StmtParent(f, stmt)
+				)
+
+				if err := InsertBefore(parent, stmt, createCallStmt("before")); err != nil {
+					return err
+				}
+
+				return InsertAfter(parent, stmt, createCallStmt("after"))
+			},
+		),
+	}))
+
+	patched := bytes.NewBuffer([]byte{})
+	FailOnError(t, printer.Fprint(patched, fileSet, f))
+
+	want := GetFunctionBody(t, data, "Want")
+	got := GetFunctionBody(t, patched.Bytes(), "Got")
+
+	AssertEquals(t, want, got)
+}
+
+func TestStmtList_errors(t *testing.T) {
+	var stmt = createCallStmt("missing")
+
+	if err := DeleteStmt(&ast.BlockStmt{}, stmt); err != ErrStmtNotFound {
+		t.Fatalf("expected ErrStmtNotFound, got: %v", err)
+	}
+
+	if err := DeleteStmt(&ast.IfStmt{}, stmt); err != ErrNoStmtList {
+		t.Fatalf("expected ErrNoStmtList, got: %v", err)
+	}
+}
+
+func createCheckErrStmt(x ast.Expr) ast.Stmt {
+	return &ast.IfStmt{
+		Init: &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{x},
+		},
+		Cond: &ast.BinaryExpr{
+			X:  ast.NewIdent("err"),
+			Op: token.NEQ,
+			Y:  ast.NewIdent("nil"),
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("err")}},
+			},
+		},
+	}
+}
+
+func createCallStmt(name string) ast.Stmt {
+	return &ast.ExprStmt{X: &ast.CallExpr{Fun: ast.NewIdent(name)}}
+}
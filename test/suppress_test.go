@@ -0,0 +1,63 @@
+package test
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestSuppressions(t *testing.T) {
+	var (
+		data         = MustReadFile(t, "suppress.go.txt")
+		fileSet      = token.NewFileSet()
+		f            = MustParse(t, fileSet, "suppress.go", data)
+		suppressions = ParseSuppressions(fileSet, f)
+		got          []string
+	)
+
+	rules, err := ParseRules([]byte("rules: [{id: no-panic, pattern: 'panic($_)', message: do not panic}]"))
+	FailOnError(t, err)
+
+	FailOnError(t, Walk(f, rules.Matchers(fileSet, f, suppressions.Filter(func(d Diagnostic) {
+		got = append(got, d.String())
+	}))))
+
+	want := []string{
+		"suppress.go:18:2: info: do not panic (no-panic)",
+		"suppress.go:31:2: info: do not panic (no-panic)",
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected diagnostics:\nwant: %v\ngot:  %v", want, got)
+	}
+
+	var unused []string
+	for _, s := range suppressions.Unused() {
+		unused = append(unused, s.String())
+	}
+
+	wantUnused := []string{
+		"suppress.go:1:1: unused suppression of other-rule",
+		"suppress.go:22:2: unused suppression of no-panic",
+		"suppress.go:31:13: invalid suppression, separate the reason from the rules by --",
+	}
+	if !reflect.DeepEqual(wantUnused, unused) {
+		t.Fatalf("unexpected unused suppressions:\nwant: %v\ngot:  %v", wantUnused, unused)
+	}
+}
+
+func TestSuppressions_fileWithReasonOnly(t *testing.T) {
+	var (
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "generated.go", []byte("//asterisk:ignore-file -- generated code\n\npackage a\n\nfunc A() { panic(1) }\n"))
+		s       = ParseSuppressions(fileSet, f)
+	)
+
+	AssertEquals(t, "[*] generated code", fmt.Sprint(s[0].Rules, " ", s[0].Reason))
+
+	if !s.Suppressed(Diagnostic{Rule: "no-panic", Pos: token.Position{Filename: "generated.go", Line: 5}}) {
+		t.Fatal("expected the diagnostic to be suppressed")
+	}
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+var errRewriteFailed = errors.New("rewrite failed")
+
+func TestWalk_transactionalRollback(t *testing.T) {
+	var (
+		data    = MustReadFile(t, "transaction.go.txt")
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "", data)
+		s1      = NodeSelections{}
+	)
+
+	err := Walk(f, PatternMatchers{
+		New(
+			[]NodeCondition{
+				s1.Select(CallExpr(IgnoreNode(), IgnoreNodes()), "call"),
+			},
+			func() error {
+				var (
+					call  = s1.CallExpr("call")
+					ident = call.Fun.(*ast.Ident)
+				)
+
+				if ident.Name == "keep" {
+					ident.Name = "kept"
+
+					return nil
+				}
+
+				ident.Name = "failed"
+				call.Args[0] = &ast.BasicLit{Kind: token.INT, Value: "3"}
+
+				return errRewriteFailed
+			},
+		),
+	}, Transactional())
+
+	var matchErrs MatchErrors
+	if !errors.As(err, &matchErrs) || !errors.Is(matchErrs[0], errRewriteFailed) {
+		t.Fatalf("expected the failing rewrite to be reported, got: %v", err)
+	}
+
+	patched := bytes.NewBuffer([]byte{})
+	FailOnError(t, printer.Fprint(patched, fileSet, f))
+
+	want := GetFunctionBody(t, data, "Want")
+	got := GetFunctionBody(t, patched.Bytes(), "Got")
+
+	AssertEquals(t, want, got)
+}
+
+func TestWalk_transactionalRollbackKeepsCommittedMatches(t *testing.T) {
+	var (
+		data    = MustReadFile(t, "transaction_commit.go.txt")
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "", data)
+		s1      = NodeSelections{}
+		added   *ast.BasicLit
+	)
+
+	err := Walk(f, PatternMatchers{
+		New(
+			[]NodeCondition{
+				s1.Select(CallExpr(IgnoreNode(), IgnoreNodes()), "call"),
+			},
+			func() error {
+				var (
+					call  = s1.CallExpr("call")
+					ident = call.Fun.(*ast.Ident)
+				)
+
+				if ident.Name == "keep" {
+					ident.Name = "kept"
+					added = &ast.BasicLit{Kind: token.INT, Value: "0"}
+					call.Args = append(call.Args, added)
+
+					return nil
+				}
+
+				added.Value = "9"
+
+				return errRewriteFailed
+			},
+		),
+	}, Transactional())
+
+	var matchErrs MatchErrors
+	if !errors.As(err, &matchErrs) || !errors.Is(matchErrs[0], errRewriteFailed) {
+		t.Fatalf("expected the failing rewrite to be reported, got: %v", err)
+	}
+
+	patched := bytes.NewBuffer([]byte{})
+	FailOnError(t, printer.Fprint(patched, fileSet, f))
+
+	want := GetFunctionBody(t, data, "Want")
+	got := GetFunctionBody(t, patched.Bytes(), "Got")
+
+	AssertEquals(t, want, got)
+}
+
+func TestWalk_onChange(t *testing.T) {
+	var (
+		f       = MustParse(t, token.NewFileSet(), "", MustReadFile(t, "transaction_commit.go.txt"))
+		s1      = NodeSelections{}
+		changed []string
+	)
+
+	err := Walk(f, PatternMatchers{
+		New(
+			[]NodeCondition{
+				CallExpr(s1.Select(IgnoreNode(), "fun"), IgnoreNodes()),
+			},
+			func() error {
+				if ident := s1.Ident("fun"); ident.Name == "keep" {
+					ident.Name = "kept"
+				}
+
+				return nil
+			},
+		),
+	}, OnChange(func(_ *Matcher, node ast.Node) {
+		changed = append(changed, node.(*ast.CallExpr).Fun.(*ast.Ident).Name)
+	}))
+	FailOnError(t, err)
+
+	AssertEquals(t, "[kept]", fmt.Sprint(changed))
+}
@@ -0,0 +1,175 @@
+package test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+var treePatterns = []string{
+	"logrus.SetLevel($level)",
+	"logrus.$method($msg)",
+	"logrus.Error($msg)",
+	"logrus.Error($_)",
+	"logrus.WithField($k, $v).Info($msg)",
+	"fmt.Println($x)",
+	"fmt.Println($x, $x)",
+	"$x + $x",
+	"$x + $y",
+	"$x",
+	"$x = $y",
+	"$x := $y",
+	"if $c { $body }",
+	"if err != nil { return err }",
+	"for $i := 0; $i < $n; $i++ { $body }",
+	"panic($_)",
+	"a.b",
+	"x.Call(a, b)",
+}
+
+func compilePatterns(t *testing.T) []*CodePattern {
+	var patterns []*CodePattern
+
+	for _, src := range treePatterns {
+		p, err := ParseCodePattern(src)
+		FailOnError(t, err)
+
+		patterns = append(patterns, p)
+	}
+
+	return patterns
+}
+
+func TestDecisionTree_matchesLikePatterns(t *testing.T) {
+	var (
+		patterns = compilePatterns(t)
+		tree     = NewDecisionTree(patterns...)
+	)
+
+	files, err := filepath.Glob(filepath.Join("resources", "*.go.txt"))
+	FailOnError(t, err)
+
+	for _, file := range files {
+		var f = MustParse(t, token.NewFileSet(), file, MustReadFile(t, filepath.Base(file)))
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			if n == nil {
+				return false
+			}
+
+			var want, got []string
+
+			for i, p := range patterns {
+				FailOnError(t, NewPattern("", []NodeCondition{p.Condition()}, func(s NodeSelections) error {
+					want = append(want, fmt.Sprintf("#%v %v", i, selected(s)))
+
+					return nil
+				}).Matcher().Match(n))
+			}
+
+			for _, m := range tree.Match(n) {
+				var s = NodeSelections{}
+				for name, node := range m.Captures {
+					var n1 = &node
+					s[name] = []**ast.Node{&n1}
+				}
+
+				got = append(got, fmt.Sprintf("#%v %v", m.Pattern, selected(s)))
+			}
+
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("%v: %T at %v: want %v, got %v", file, n, n.Pos(), want, got)
+			}
+
+			return true
+		})
+	}
+}
+
+func selected(s NodeSelections) string {
+	var names []string
+	for name := range s {
+		names = append(names, fmt.Sprintf("%v=%p", name, **s[name][0]))
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
+
+func TestDecisionTree_String(t *testing.T) {
+	var patterns []*CodePattern
+
+	for _, src := range []string{"logrus.Error($msg)", "logrus.Info($msg)", "$x + $x"} {
+		p, err := ParseCodePattern(src)
+		FailOnError(t, err)
+
+		patterns = append(patterns, p)
+	}
+
+	AssertEquals(t, string(MustReadFile(t, "tree.txt")), NewDecisionTree(patterns...).String())
+}
+
+func TestRuleTree_Matchers(t *testing.T) {
+	rules, err := LoadRules(filepath.Join("resources", "rules.yaml"))
+	FailOnError(t, err)
+
+	var (
+		tree  = NewRuleTree(rules)
+		files = []string{filepath.Join("resources", "rules.go.txt"), filepath.Join("resources", "suppress.go.txt")}
+		want  = results(t, NewEngine(rules.Matchers, WithSuppressions()).RunFiles(files))
+		got   = results(t, NewEngine(tree.Matchers, WithSuppressions()).RunFiles(files))
+	)
+
+	if len(want) == 0 || !reflect.DeepEqual(want, got) {
+		t.Fatalf("expected the tree to report like the rules:\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+// BenchmarkRuleTree compares the naive matchers of many rules sharing prefixes with the matchers of their tree.
+func BenchmarkRuleTree(b *testing.B) {
+	var (
+		sb      strings.Builder
+		methods = []string{"Trace", "Debug", "Info", "Print", "Warn", "Warning", "Error", "Fatal", "Panic"}
+	)
+
+	sb.WriteString("rules:\n")
+
+	for _, m := range methods {
+		for _, suffix := range []string{"", "f", "ln"} {
+			fmt.Fprintf(&sb, "  - {id: logrus-%[1]v%[2]v, pattern: 'logrus.%[1]v%[2]v($msg)', message: m}\n", m, suffix)
+			fmt.Fprintf(&sb, "  - {id: logrus-%[1]v%[2]v-2, pattern: 'logrus.%[1]v%[2]v($a, $b)', message: m}\n", m, suffix)
+		}
+
+		fmt.Fprintf(&sb, "  - {id: logrus-field-%[1]v, pattern: 'logrus.WithField($k, $v).%[1]v($msg)', message: m}\n", m)
+	}
+
+	rules, err := ParseRules([]byte(sb.String()))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var (
+		fileSet = token.NewFileSet()
+		f       = generatedFile(b, 1000)
+		tree    = NewRuleTree(rules)
+		report  = func(Diagnostic) {}
+	)
+
+	for name, factory := range map[string]MatcherFactory{"naive": rules.Matchers, "tree": tree.Matchers} {
+		b.Run(fmt.Sprintf("%v/rules=%v", name, len(rules)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := Walk(f, factory(fileSet, f, report)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"go/parser"
 	"go/printer"
 	"go/token"
-	"io/ioutil"
+	"os"
 	"path"
 	"strings"
 	"testing"
@@ -21,7 +21,7 @@ func MustParse(t *testing.T, fileSet *token.FileSet, file string, data []byte) *
 }
 
 func MustReadFile(t *testing.T, file string) []byte {
-	b, err := ioutil.ReadFile(path.Join("resources", file))
+	b, err := os.ReadFile(path.Join("resources", file))
 	FailOnError(t, err)
 
 	return b
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"testing"
+
+	. "github.com/Oppodelldog/asterisk"
+)
+
+func TestWalk_verifyRejectsIllTypedRewrites(t *testing.T) {
+	var (
+		data    = MustReadFile(t, "verify.go.txt")
+		fileSet = token.NewFileSet()
+		f       = MustParse(t, fileSet, "verify.go", data)
+		s1      = NodeSelections{}
+		s2      = NodeSelections{}
+	)
+
+	err := Walk(f, PatternMatchers{
+		New(
+			[]NodeCondition{
+				CallExpr(
+					SelectorExpr(Ident("strings"), s1.Select(Ident("ToUpper"), "method")),
+					IgnoreNodes(),
+				),
+			},
+			func() error {
+				s1.Ident("method").Name = "ToLower"
+
+				return nil
+			},
+		).Named("to-lower"),
+		New(
+			[]NodeCondition{
+				s2.Select(
+					CallExpr(SelectorExpr(Ident("strings"), Ident("TrimSpace")), IgnoreNodes()),
+					"call",
+				),
+			},
+			func() error {
+				var call = s2.CallExpr("call")
+				call.Args = append(call.Args, &ast.BasicLit{Kind: token.STRING, Value: `" "`})
+
+				return nil
+			},
+		).Named("trim-cutset"),
+	}, WithFileSet(fileSet), Verify(NewVerifier(fileSet, []*ast.File{f}, nil)))
+
+	var matchErrs MatchErrors
+	if !errors.As(err, &matchErrs) || len(matchErrs) != 2 {
+		t.Fatalf("expected both trim-cutset rewrites to be rejected, got: %v", err)
+	}
+
+	for _, matchErr := range matchErrs {
+		var typeErr *TypeCheckError
+		if matchErr.Rule != "trim-cutset" || !errors.As(matchErr, &typeErr) {
+			t.Fatalf("expected type check error of rule trim-cutset, got: %v", matchErr)
+		}
+	}
+
+	patched := bytes.NewBuffer([]byte{})
+	FailOnError(t, printer.Fprint(patched, fileSet, f))
+
+	want := GetFunctionBody(t, data, "Want")
+	got := GetFunctionBody(t, patched.Bytes(), "Got")
+
+	AssertEquals(t, want, got)
+}
+
+type countingImporter struct {
+	types.Importer
+	imports int
+}
+
+func (i *countingImporter) Import(path string) (*types.Package, error) {
+	i.imports++
+
+	return i.Importer.Import(path)
+}
+
+func TestWalk_verifyChecksOncePerWalk(t *testing.T) {
+	var (
+		fileSet  = token.NewFileSet()
+		f        = MustParse(t, fileSet, "verify.go", MustReadFile(t, "verify.go.txt"))
+		counter  = &countingImporter{Importer: importer.ForCompiler(fileSet, "source", nil)}
+		verifier = NewVerifier(fileSet, []*ast.File{f}, &types.Config{Importer: counter})
+		swapped  = map[string]string{"ToUpper": "ToLower", "ToLower": "ToUpper"}
+		s1       = NodeSelections{}
+	)
+
+	err := Walk(f, PatternMatchers{
+		New(
+			[]NodeCondition{
+				CallExpr(SelectorExpr(Ident("strings"), s1.Select(IgnoreNode(), "method")), IgnoreNodes()),
+			},
+			func() error {
+				var method = s1.Ident("method")
+				if name, ok := swapped[method.Name]; ok {
+					method.Name = name
+				}
+
+				return nil
+			},
+		).Named("swap-case"),
+	}, Verify(verifier))
+	FailOnError(t, err)
+
+	AssertEquals(t, "2", fmt.Sprint(counter.imports))
+}
@@ -0,0 +1,402 @@
+package asterisk
+
+import (
+	"errors"
+	"go/ast"
+	"reflect"
+)
+
+var (
+	objectType = reflect.TypeOf((*ast.Object)(nil))
+	scopeType  = reflect.TypeOf((*ast.Scope)(nil))
+)
+
+// transaction journals the mutations of the matches of a walk. It restores the state of the tree below root
+// from before a match if its processing fails or panics.
+// Only the scope of a match is journaled and compared after it: the subtree of the parent of its first node,
+// the ancestors of that parent, the subtrees of the selected nodes and the nodes earlier matches created.
+// Mutations of other nodes are not rolled back.
+type transaction struct {
+	root     ast.Node
+	journal  journal
+	verifier *Verifier
+	onChange func(pm *Matcher, node ast.Node)
+	// path holds the visited node and its ancestors.
+	path []ast.Node
+	// created holds the nodes committed matches added to the tree.
+	created []reflect.Value
+	// changes holds the committed changes to verify, it is only filled if a verifier is given.
+	changes []change
+}
+
+// change holds the state of the nodes a committed match changed, before and after the match.
+type change struct {
+	pm     *Matcher
+	first  ast.Node
+	before journal
+	after  journal
+}
+
+func newTransaction(root ast.Node, verifier *Verifier, onChange func(pm *Matcher, node ast.Node)) *transaction {
+	return &transaction{root: root, journal: journal{}, verifier: verifier, onChange: onChange}
+}
+
+// enter is called for each visited node before it is matched.
+func (t *transaction) enter(n ast.Node) {
+	t.path = append(t.path, n)
+}
+
+// leave is called after the children of the visited node were walked, or right after it was matched
+// if they are skipped.
+func (t *transaction) leave() {
+	t.path = t.path[:len(t.path)-1]
+}
+
+func (t *transaction) process(pm *Matcher, first ast.Node, s NodeSelections) (err error) {
+	var sc = t.scope(first, s)
+
+	t.journal.record(sc)
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.journal.rollback(sc)
+			panic(r)
+		}
+	}()
+
+	err = process(pm, first, s)
+	if err != nil && !isControl(err) {
+		t.journal.rollback(sc)
+
+		return err
+	}
+
+	var before, after, created = t.journal.commit(sc)
+
+	t.created = append(t.created, created...)
+
+	if len(before) == 0 {
+		return err
+	}
+
+	if t.verifier != nil {
+		t.changes = append(t.changes, change{pm: pm, first: first, before: before, after: after})
+	}
+
+	if t.onChange != nil {
+		t.onChange(pm, first)
+	}
+
+	return err
+}
+
+// scope returns the nodes a match starting at first may change. If first is not on the path of the visited
+// node, the whole tree is in scope.
+func (t *transaction) scope(first ast.Node, s NodeSelections) scope {
+	var (
+		sc     = scope{parents: t.created}
+		parent = len(t.path)
+	)
+
+	for i := len(t.path) - 1; i >= 0; i-- {
+		if t.path[i] == first {
+			parent = i - 1
+
+			break
+		}
+	}
+
+	switch {
+	case parent == len(t.path):
+		sc.nodes = append(sc.nodes, reflect.ValueOf(t.root))
+	case parent < 0:
+		sc.nodes = append(sc.nodes, reflect.ValueOf(first))
+	default:
+		sc.nodes = append(sc.nodes, reflect.ValueOf(t.path[parent]))
+
+		for _, n := range t.path[:parent] {
+			sc.parents = append(sc.parents, reflect.ValueOf(n))
+		}
+	}
+
+	for _, selected := range s {
+		for _, n := range selected {
+			if *n != nil {
+				sc.nodes = append(sc.nodes, reflect.ValueOf(**n))
+			}
+		}
+	}
+
+	return sc
+}
+
+// verify type-checks the package once for all committed changes. If they introduced new type errors,
+// the changes are rolled back and applied again one by one. A change introducing new type errors is
+// rolled back and reported, as is a change of nodes that a rejected change changed as well.
+func (t *transaction) verify() MatchErrors {
+	if len(t.changes) == 0 || t.verifier.verify() == nil {
+		return nil
+	}
+
+	for i := len(t.changes) - 1; i >= 0; i-- {
+		t.changes[i].before.restore()
+	}
+
+	var (
+		errs     MatchErrors
+		rejected = map[ptrKey]error{}
+	)
+
+	for _, c := range t.changes {
+		var err = c.conflict(rejected)
+		if err == nil {
+			c.after.restore()
+
+			if err = t.verifier.verify(); err != nil {
+				c.before.restore()
+			}
+		}
+
+		if err != nil {
+			for key := range c.after {
+				rejected[key] = err
+			}
+
+			errs = append(errs, &MatchError{Rule: c.pm.pattern.name, Node: c.first, Err: err})
+		}
+	}
+
+	return errs
+}
+
+// conflict returns the error of a rejected change of a node the change changed as well.
+func (c change) conflict(rejected map[ptrKey]error) error {
+	for key := range c.after {
+		if err, ok := rejected[key]; ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isControl reports whether err only controls the walk, like SkipChildren and Stop.
+func isControl(err error) bool {
+	return errors.Is(err, SkipChildren) || errors.Is(err, Stop)
+}
+
+// journal holds a shallow copy of every node in the scope of the matches of a tree, keyed by the pointer
+// to the node. Nodes removed from the tree stay in the journal.
+type journal map[ptrKey]journalEntry
+
+// ptrKey identifies a node by its type and address.
+type ptrKey struct {
+	t   reflect.Type
+	ptr uintptr
+}
+
+type journalEntry struct {
+	ptr   reflect.Value
+	saved reflect.Value
+}
+
+// scope holds the nodes a match may change: all nodes reachable from nodes, and the parents themselves.
+type scope struct {
+	nodes   []reflect.Value
+	parents []reflect.Value
+}
+
+// record adds the nodes in the given scope that are not journaled yet.
+func (j journal) record(sc scope) {
+	j.scan(sc, func(key ptrKey, v reflect.Value) bool {
+		if _, ok := j[key]; !ok {
+			j[key] = journalEntry{ptr: v, saved: shallowCopy(v.Elem())}
+		}
+
+		return false
+	})
+}
+
+// changed returns the keys of the nodes in the given scope that differ from their recorded state.
+func (j journal) changed(sc scope) []ptrKey {
+	var keys []ptrKey
+
+	j.scan(sc, func(key ptrKey, _ reflect.Value) bool {
+		if e, ok := j[key]; ok && e.changed() {
+			keys = append(keys, key)
+
+			return true
+		}
+
+		return false
+	})
+
+	return keys
+}
+
+// rollback writes the recorded state back into the changed nodes of the scope, so existing pointers stay valid.
+func (j journal) rollback(sc scope) {
+	for _, key := range j.changed(sc) {
+		j[key].restore()
+	}
+}
+
+// restore writes the recorded state back into all nodes.
+func (j journal) restore() {
+	for _, e := range j {
+		e.restore()
+	}
+}
+
+// commit records the current state of the changed nodes of the scope and adds the nodes they refer to
+// for the first time. It returns the entries of the changed nodes before and after the commit, and the
+// added nodes.
+func (j journal) commit(sc scope) (before, after journal, added []reflect.Value) {
+	before, after = journal{}, journal{}
+
+	for _, key := range j.changed(sc) {
+		var e = j[key]
+
+		before[key] = e
+		after[key] = journalEntry{ptr: e.ptr, saved: shallowCopy(e.ptr.Elem())}
+		j[key] = after[key]
+	}
+
+	var s = scanner{j: j, seen: map[ptrKey]bool{}, f: func(key ptrKey, v reflect.Value) bool {
+		if _, ok := j[key]; !ok {
+			j[key] = journalEntry{ptr: v, saved: shallowCopy(v.Elem())}
+			added = append(added, v)
+		}
+
+		return false
+	}}
+
+	for _, e := range after {
+		s.fields(e.ptr.Elem())
+	}
+
+	return before, after, added
+}
+
+// restore writes the recorded state into the node. The recorded state is copied, so it is not affected
+// by later in place modifications of the node.
+func (e journalEntry) restore() {
+	e.ptr.Elem().Set(shallowCopy(e.saved))
+}
+
+// changed reports whether the node differs from its recorded state. Slices are compared by their elements,
+// maps by identity.
+func (e journalEntry) changed() bool {
+	var v = e.ptr.Elem()
+
+	for i := 0; i < v.NumField(); i++ {
+		var f, saved = v.Field(i), e.saved.Field(i)
+
+		switch f.Kind() {
+		case reflect.Slice:
+			if f.IsNil() != saved.IsNil() || f.Len() != saved.Len() {
+				return true
+			}
+
+			for k := 0; k < f.Len(); k++ {
+				if !f.Index(k).Equal(saved.Index(k)) {
+					return true
+				}
+			}
+		case reflect.Map:
+			if f.Pointer() != saved.Pointer() {
+				return true
+			}
+		default:
+			if !f.Equal(saved) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// scan calls f once for each node in the given scope. The fields of parents are only scanned if f reports
+// the parent changed. The recorded state of changed nodes is scanned as well, so nodes that were removed
+// from the tree are found.
+func (j journal) scan(sc scope, f func(key ptrKey, v reflect.Value) bool) {
+	var s = scanner{j: j, f: f, seen: map[ptrKey]bool{}}
+
+	for _, v := range sc.parents {
+		s.node(v, false)
+	}
+
+	for _, v := range sc.nodes {
+		s.value(v)
+	}
+}
+
+type scanner struct {
+	j    journal
+	f    func(key ptrKey, v reflect.Value) bool
+	seen map[ptrKey]bool
+}
+
+func (s scanner) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			s.value(v.Elem())
+		}
+	case reflect.Ptr:
+		s.node(v, true)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			s.value(v.Index(i))
+		}
+	}
+}
+
+func (s scanner) node(v reflect.Value, deep bool) {
+	if v.IsNil() || v.Type() == objectType || v.Type() == scopeType || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	var key = ptrKey{t: v.Type(), ptr: v.Pointer()}
+	if s.seen[key] {
+		return
+	}
+
+	s.seen[key] = true
+
+	var changed = s.f(key, v)
+	if !deep && !changed {
+		return
+	}
+
+	s.fields(v.Elem())
+
+	if e, ok := s.j[key]; changed && ok {
+		s.fields(e.saved)
+	}
+}
+
+func (s scanner) fields(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		s.value(v.Field(i))
+	}
+}
+
+// shallowCopy copies the given struct, slices are copied into new backing arrays so in place
+// modifications of the original slices do not affect the copy.
+func shallowCopy(v reflect.Value) reflect.Value {
+	var c = reflect.New(v.Type()).Elem()
+
+	c.Set(v)
+
+	for i := 0; i < c.NumField(); i++ {
+		if f := c.Field(i); f.Kind() == reflect.Slice && !f.IsNil() {
+			s := reflect.MakeSlice(f.Type(), f.Len(), f.Len())
+			reflect.Copy(s, f)
+			f.Set(s)
+		}
+	}
+
+	return c
+}
@@ -0,0 +1,497 @@
+package asterisk
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DecisionTree matches many code patterns at once. The patterns are compiled into a tree of tests on the
+// nodes and values of the ast in depth-first order, so tests shared by patterns, like the type of the root
+// node or the name of a package, are run once per node and the tree fans out only where the patterns diverge.
+// Variables are edges consuming any node. A DecisionTree is immutable and safe for concurrent use.
+type DecisionTree struct {
+	patterns []*CodePattern
+	root     *treeNode
+}
+
+// treeNode is a node of a DecisionTree, the patterns of its leaves are fully matched when it is reached.
+type treeNode struct {
+	edges    map[treeKey]*treeNode
+	wildcard *treeNode
+	leaves   []treeLeaf
+}
+
+// treeLeaf holds a pattern and the names of the variables along the wildcard edges leading to it.
+type treeLeaf struct {
+	pattern int
+	vars    []string
+}
+
+// treeKey identifies the type and the scalar value of a node, a value, or the length of a slice.
+type treeKey struct {
+	t reflect.Type
+	s string
+	n int64
+}
+
+// TreeMatch is a match of a pattern of a DecisionTree.
+type TreeMatch struct {
+	// Pattern is the index of the matched pattern.
+	Pattern int
+	// Captures holds the nodes captured by the variables of the pattern.
+	Captures map[string]ast.Node
+}
+
+// stackPool holds the stacks of values matched against a DecisionTree.
+var stackPool = sync.Pool{New: func() interface{} { return new([]reflect.Value) }}
+
+// NewDecisionTree compiles the given patterns into a DecisionTree.
+func NewDecisionTree(patterns ...*CodePattern) *DecisionTree {
+	var t = &DecisionTree{patterns: patterns, root: newTreeNode()}
+
+	for i, p := range patterns {
+		var (
+			node  = t.root
+			stack = []reflect.Value{reflect.ValueOf(p.node)}
+			vars  []string
+		)
+
+		for len(stack) > 0 {
+			var v = stack[len(stack)-1]
+
+			stack = stack[:len(stack)-1]
+
+			if name, ok := valueVar(v); ok {
+				if node.wildcard == nil {
+					node.wildcard = newTreeNode()
+				}
+
+				node, vars = node.wildcard, append(vars, name)
+
+				continue
+			}
+
+			var key treeKey
+
+			key, stack = expand(v, stack)
+
+			if node.edges[key] == nil {
+				node.edges[key] = newTreeNode()
+			}
+
+			node = node.edges[key]
+		}
+
+		node.leaves = append(node.leaves, treeLeaf{pattern: i, vars: vars})
+	}
+
+	return t
+}
+
+func newTreeNode() *treeNode {
+	return &treeNode{edges: map[treeKey]*treeNode{}}
+}
+
+// valueVar returns the variable name if the given pattern value is a variable.
+func valueVar(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return "", false
+	}
+
+	if node, ok := v.Interface().(ast.Node); ok {
+		return patternVar(node)
+	}
+
+	return "", false
+}
+
+// expand returns the key of the given value and pushes its children onto the stack in reverse order,
+// so they are popped in order. Fields ignored by code patterns are skipped.
+func expand(v reflect.Value, stack []reflect.Value) (treeKey, []reflect.Value) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return treeKey{}, stack
+		}
+
+		v = v.Elem()
+	}
+
+	var key = treeKey{t: v.Type()}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			key.n = -1
+
+			return key, stack
+		}
+
+		if e := v.Elem(); e.Kind() == reflect.Struct {
+			return key, pushFields(e, stack)
+		}
+
+		return key, append(stack, v.Elem())
+	case reflect.Struct:
+		return key, pushFields(v, stack)
+	case reflect.Slice:
+		key.n = int64(v.Len())
+
+		for i := v.Len() - 1; i >= 0; i-- {
+			stack = append(stack, v.Index(i))
+		}
+	case reflect.String:
+		key.s = v.String()
+	case reflect.Bool:
+		if v.Bool() {
+			key.n = 1
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		key.n = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		key.n = int64(v.Uint())
+	}
+
+	return key, stack
+}
+
+func pushFields(v reflect.Value, stack []reflect.Value) []reflect.Value {
+	for i := v.NumField() - 1; i >= 0; i-- {
+		if f := v.Field(i); !ignoredField(f.Type()) {
+			stack = append(stack, f)
+		}
+	}
+
+	return stack
+}
+
+// Patterns returns the patterns of the tree.
+func (t *DecisionTree) Patterns() []*CodePattern {
+	return t.patterns
+}
+
+// Match returns the matches of all patterns on the given node, ordered by pattern.
+func (t *DecisionTree) Match(n ast.Node) []TreeMatch {
+	if n == nil || (t.root.wildcard == nil && t.root.edges[treeKey{t: reflect.TypeOf(n)}] == nil) {
+		return nil
+	}
+
+	var (
+		matches []TreeMatch
+		stack   = stackPool.Get().(*[]reflect.Value)
+	)
+
+	*stack = append(*stack, reflect.ValueOf(n))
+
+	t.match(t.root, *stack, nil, &matches)
+
+	clear(*stack)
+	*stack = (*stack)[:0]
+	stackPool.Put(stack)
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Pattern < matches[j].Pattern
+	})
+
+	return matches
+}
+
+// match follows the edges of node matching the values of the stack, captured holds the nodes consumed by
+// the wildcard edges followed so far.
+func (t *DecisionTree) match(node *treeNode, stack, captured []reflect.Value, matches *[]TreeMatch) {
+	if len(stack) == 0 {
+		for _, leaf := range node.leaves {
+			if captures, ok := leaf.captures(captured); ok {
+				*matches = append(*matches, TreeMatch{Pattern: leaf.pattern, Captures: captures})
+			}
+		}
+
+		return
+	}
+
+	var (
+		v    = stack[len(stack)-1]
+		rest = stack[:len(stack)-1]
+	)
+
+	if node.wildcard != nil && capturable(v) {
+		var wildcardStack = rest
+
+		// the stack is modified by the edges matched below, so the wildcard branch needs its own copy.
+		if len(node.edges) > 0 {
+			wildcardStack = append([]reflect.Value(nil), rest...)
+		}
+
+		t.match(node.wildcard, wildcardStack, append(captured, v), matches)
+	}
+
+	if len(node.edges) == 0 {
+		return
+	}
+
+	key, next := expand(v, rest)
+	if child := node.edges[key]; child != nil {
+		t.match(child, next, captured, matches)
+	}
+}
+
+// capturable reports whether a variable can capture the given value, it has to be a non-nil node.
+func capturable(v reflect.Value) bool {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return false
+	}
+
+	_, ok := v.Interface().(ast.Node)
+
+	return ok
+}
+
+// captures maps the captured values to the variables of the leaf. A variable used multiple times has to
+// capture equal nodes.
+func (leaf treeLeaf) captures(captured []reflect.Value) (map[string]ast.Node, bool) {
+	var captures = map[string]ast.Node{}
+
+	for i, name := range leaf.vars {
+		if name == wildcard {
+			continue
+		}
+
+		if c, ok := captures[name]; ok {
+			if !matchPattern(reflect.ValueOf(c), captured[i], map[string]ast.Node{}) {
+				return nil, false
+			}
+
+			continue
+		}
+
+		captures[name] = captured[i].Interface().(ast.Node)
+	}
+
+	return captures, true
+}
+
+// treeEvaluation is the match of a DecisionTree on the last node it was evaluated for during a walk.
+type treeEvaluation struct {
+	node    ast.Node
+	matches []TreeMatch
+}
+
+// matches returns the matches of the tree on the given node, the tree is matched once per node and walk.
+// Profiled walks record the match apart from the rules sharing it.
+func (ev *evaluation) matches(t *DecisionTree, n ast.Node) []TreeMatch {
+	if ev.trees == nil {
+		return t.Match(n)
+	}
+
+	var last, ok = ev.trees[t]
+	if !ok {
+		last = &treeEvaluation{}
+		ev.trees[t] = last
+	} else if last.node == n {
+		return last.matches
+	}
+
+	if last.node = n; ev.profile == nil {
+		last.matches = t.Match(n)
+	} else {
+		ev.profile.evalShared("DecisionTree", func() bool {
+			last.matches = t.Match(n)
+
+			return len(last.matches) > 0
+		})
+	}
+
+	return last.matches
+}
+
+// Conditions returns a NodeCondition for each pattern of the tree, which selects the captured nodes
+// like CodePattern.Condition does. The conditions of a walk share the match of the tree,
+// it is evaluated once per node.
+func (t *DecisionTree) Conditions() []NodeCondition {
+	var conditions = make([]NodeCondition, len(t.patterns))
+
+	for i, p := range t.patterns {
+		var i = i
+
+		conditions[i] = NodeCondition{
+			eval: func(ev *evaluation, n ast.Node) bool {
+				for _, m := range ev.matches(t, n) {
+					if m.Pattern != i {
+						continue
+					}
+
+					for name, node := range m.Captures {
+						ev.selectNodes(name, node)
+					}
+
+					return true
+				}
+
+				return false
+			},
+			label: "Pattern(" + p.src + ")",
+		}
+
+		if _, ok := patternVar(p.node); !ok {
+			conditions[i].kinds = kindSet([]ast.Node{p.node})
+		}
+	}
+
+	return conditions
+}
+
+// String returns the tree in an indented form, chains of single edges are printed on one line.
+// Wildcard edges are printed as $, leaves as "=> #index pattern".
+func (t *DecisionTree) String() string {
+	var sb strings.Builder
+
+	t.print(&sb, t.root, 0)
+
+	return sb.String()
+}
+
+func (t *DecisionTree) print(sb *strings.Builder, node *treeNode, depth int) {
+	for _, leaf := range node.leaves {
+		fmt.Fprintf(sb, "%v=> #%v %v\n", strings.Repeat("  ", depth), leaf.pattern, t.patterns[leaf.pattern])
+	}
+
+	type edge struct {
+		label string
+		node  *treeNode
+	}
+
+	var edges []edge
+
+	for key, child := range node.edges {
+		edges = append(edges, edge{label: key.String(), node: child})
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].label < edges[j].label
+	})
+
+	if node.wildcard != nil {
+		edges = append(edges, edge{label: "$", node: node.wildcard})
+	}
+
+	for _, e := range edges {
+		var labels = []string{e.label}
+
+		for len(e.node.leaves) == 0 && len(e.node.edges)+countWildcard(e.node) == 1 {
+			if e.node.wildcard != nil {
+				labels, e.node = append(labels, "$"), e.node.wildcard
+
+				continue
+			}
+
+			for key, child := range e.node.edges {
+				labels, e.node = append(labels, key.String()), child
+			}
+		}
+
+		fmt.Fprintf(sb, "%v%v\n", strings.Repeat("  ", depth), strings.Join(labels, " "))
+		t.print(sb, e.node, depth+1)
+	}
+}
+
+func countWildcard(node *treeNode) int {
+	if node.wildcard != nil {
+		return 1
+	}
+
+	return 0
+}
+
+func (k treeKey) String() string {
+	switch {
+	case k.t == nil:
+		return "nil"
+	case k.t.Kind() == reflect.Ptr && k.n == -1:
+		return fmt.Sprintf("%v(nil)", k.t)
+	case k.t.Kind() == reflect.Ptr || k.t.Kind() == reflect.Struct:
+		return k.t.String()
+	case k.t.Kind() == reflect.Slice:
+		return fmt.Sprintf("%v[%v]", k.t, k.n)
+	case k.t.Kind() == reflect.String:
+		return fmt.Sprintf("%q", k.s)
+	}
+
+	var v = reflect.New(k.t).Elem()
+
+	switch k.t.Kind() {
+	case reflect.Bool:
+		v.SetBool(k.n == 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(k.n))
+	default:
+		v.SetInt(k.n)
+	}
+
+	return fmt.Sprintf("%v(%v)", k.t, v)
+}
+
+// RuleTree matches the code patterns of many rules with a single DecisionTree.
+type RuleTree struct {
+	rules    Rules
+	tree     *DecisionTree
+	patterns map[*Rule]int
+}
+
+// NewRuleTree compiles the patterns of the given compiled rules into a DecisionTree.
+func NewRuleTree(rules Rules) *RuleTree {
+	var (
+		t        = &RuleTree{rules: rules, patterns: map[*Rule]int{}}
+		patterns []*CodePattern
+	)
+
+	for _, r := range rules {
+		if r.Check == nil {
+			t.patterns[r] = len(patterns)
+			patterns = append(patterns, r.pattern)
+		}
+	}
+
+	t.tree = NewDecisionTree(patterns...)
+
+	return t
+}
+
+// Tree returns the DecisionTree of the rules.
+func (t *RuleTree) Tree() *DecisionTree {
+	return t.tree
+}
+
+// Matchers returns PatternMatchers that report the same diagnostics as Rules.Matchers,
+// but evaluate the DecisionTree once per node instead of each pattern on its own.
+func (t *RuleTree) Matchers(fileSet *token.FileSet, file *ast.File, report ReportFunc) PatternMatchers {
+	var (
+		pms        = make(PatternMatchers, len(t.rules))
+		conditions = t.tree.Conditions()
+	)
+
+	for i, r := range t.rules {
+		if j, ok := t.patterns[r]; ok {
+			pms[i] = r.matcher(fileSet, file, report, conditions[j])
+		} else {
+			pms[i] = r.Matcher(fileSet, file, report)
+		}
+	}
+
+	return pms
+}
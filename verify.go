@@ -0,0 +1,93 @@
+package asterisk
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// TypeCheckError is returned for a rewrite that introduced new type errors.
+type TypeCheckError struct {
+	Errors []types.Error
+}
+
+func (e *TypeCheckError) Error() string {
+	var msgs = make([]string, len(e.Errors))
+	for i := range e.Errors {
+		msgs[i] = e.Errors[i].Msg
+	}
+
+	return "rewrite introduced type errors: " + strings.Join(msgs, "; ")
+}
+
+// Verifier type-checks a package after rewrites to detect rewrites that break compilation.
+type Verifier struct {
+	fileSet *token.FileSet
+	files   []*ast.File
+	config  types.Config
+	known   map[typeErrorKey]bool
+}
+
+// typeErrorKey identifies a type error by its position and message.
+type typeErrorKey struct {
+	pos token.Pos
+	msg string
+}
+
+// NewVerifier returns a Verifier for the package consisting of the given files.
+// The type errors the package already has are recorded by position and message, only new errors are reported
+// later on.
+// If config is nil, packages are imported from source.
+func NewVerifier(fileSet *token.FileSet, files []*ast.File, config *types.Config) *Verifier {
+	var v = &Verifier{fileSet: fileSet, files: files}
+
+	if config != nil {
+		v.config = *config
+	} else {
+		v.config.Importer = importer.ForCompiler(fileSet, "source", nil)
+	}
+
+	v.known = map[typeErrorKey]bool{}
+	for _, err := range v.Check() {
+		v.known[typeErrorKey{pos: err.Pos, msg: err.Msg}] = true
+	}
+
+	return v
+}
+
+// Check type-checks the package and returns all type errors.
+func (v *Verifier) Check() []types.Error {
+	var (
+		errs   []types.Error
+		config = v.config
+	)
+
+	config.Error = func(err error) {
+		if typeErr, ok := err.(types.Error); ok {
+			errs = append(errs, typeErr)
+		}
+	}
+
+	_, _ = config.Check(v.files[0].Name.Name, v.fileSet, v.files, nil)
+
+	return errs
+}
+
+// verify returns a *TypeCheckError if the package has type errors that were not known before.
+func (v *Verifier) verify() error {
+	var added []types.Error
+
+	for _, err := range v.Check() {
+		if !v.known[typeErrorKey{pos: err.Pos, msg: err.Msg}] {
+			added = append(added, err)
+		}
+	}
+
+	if len(added) > 0 {
+		return &TypeCheckError{Errors: added}
+	}
+
+	return nil
+}
@@ -1,17 +1,228 @@
 package asterisk
 
 import (
+	"context"
+	"errors"
 	"go/ast"
+	"go/token"
+	"time"
 )
 
-func Walk(f ast.Node, pms PatternMatchers) {
+var (
+	// SkipChildren is returned by the processMatch function of a Matcher to skip the children
+	// of the node that completed the match. It is not reported as error.
+	SkipChildren = errors.New("skip children")
+	// Stop is returned by the processMatch function of a Matcher to end the walk. It is not reported as error,
+	// the node that stopped the walk is not passed to the remaining matchers.
+	Stop = errors.New("stop walk")
+)
+
+// WalkOption configures Walk.
+type WalkOption func(*walkConfig)
+
+type walkConfig struct {
+	fileSet       *token.FileSet
+	stopOnError   bool
+	transactional bool
+	verifier      *Verifier
+	onChange      func(pm *Matcher, node ast.Node)
+	interceptor   Interceptor
+	skip          NodeCondition
+	ctx           context.Context
+	maxMatches    int
+	timeout       time.Duration
+	profiler      *Profiler
+}
+
+// WithFileSet resolves the positions of failing matches using the given FileSet.
+func WithFileSet(fileSet *token.FileSet) WalkOption {
+	return func(c *walkConfig) {
+		c.fileSet = fileSet
+	}
+}
+
+// StopOnError aborts the walk at the first failing match.
+// By default the walk continues and all errors are collected.
+func StopOnError() WalkOption {
+	return func(c *walkConfig) {
+		c.stopOnError = true
+	}
+}
+
+// Transactional rolls back all mutations a match applied to the tree if its processing fails,
+// so the tree is left exactly as it was before that match.
+func Transactional() WalkOption {
+	return func(c *walkConfig) {
+		c.transactional = true
+	}
+}
+
+// Verify type-checks the package of the walked file after the walk using the given Verifier.
+// If the rewrites introduced new type errors, they are checked one by one, so only the rewrites that
+// introduce new type errors are rolled back and reported as *TypeCheckError.
+// Verify implies Transactional.
+func Verify(verifier *Verifier) WalkOption {
+	return func(c *walkConfig) {
+		c.transactional = true
+		c.verifier = verifier
+	}
+}
+
+// OnChange calls the given function after each match that changed the tree, with the first node of the match.
+// The changes are found by the journal of a transactional walk, so OnChange implies Transactional.
+func OnChange(f func(pm *Matcher, node ast.Node)) WalkOption {
+	return func(c *walkConfig) {
+		c.transactional = true
+		c.onChange = f
+	}
+}
+
+// SkipIf does not descend into the children of nodes matching the given condition,
+// e.g. to ignore function literals. The nodes themselves are still matched.
+func SkipIf(skip NodeCondition) WalkOption {
+	return func(c *walkConfig) {
+		c.skip = skip
+	}
+}
+
+// Interceptor is called for each completed match instead of processing it directly.
+// It receives the first node of the match and the nodes selected during the match,
+// and has to call process to process the match.
+type Interceptor func(pm *Matcher, node ast.Node, s NodeSelections, process func() error) error
+
+// Intercept calls the given Interceptor for each completed match.
+func Intercept(interceptor Interceptor) WalkOption {
+	return func(c *walkConfig) {
+		c.interceptor = interceptor
+	}
+}
+
+// Walk traverses the given node and matches all pattern matchers against each visited node.
+// The errors of failing matches are returned as MatchErrors.
+// Matches may return SkipChildren or Stop to prune the walk. Conditions cannot signal either,
+// subtrees are skipped by condition using SkipIf.
+// If the walk is aborted by its context or a limit, that error is returned along with the MatchErrors.
+func Walk(f ast.Node, pms PatternMatchers, opts ...WalkOption) error {
+	var (
+		cfg     walkConfig
+		errs    MatchErrors
+		stopped bool
+	)
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var (
+		processMatch processFunc = process
+		tx           *transaction
+	)
+
+	if cfg.transactional {
+		tx = newTransaction(f, cfg.verifier, cfg.onChange)
+		processMatch = tx.process
+	}
+
+	if cfg.interceptor != nil {
+		processMatch = intercept(cfg.interceptor, processMatch)
+	}
+
+	var limits = newWalkLimits(cfg)
+	if cfg.maxMatches > 0 {
+		processMatch = limits.limitMatches(cfg.maxMatches, processMatch)
+	}
+
+	var ev = newEvaluation()
+
+	if cfg.profiler != nil {
+		ev.profile = newWalkProfile()
+		defer cfg.profiler.merge(ev.profile)
+	}
+
+	var d = newDispatcher(pms, ev)
+
 	ast.Inspect(f, func(n ast.Node) bool {
 		if n == nil {
-			return true
+			if tx != nil {
+				tx.leave()
+			}
+
+			return !stopped
 		}
 
-		pms.Match(n)
+		if stopped {
+			return false
+		}
+
+		if limits.exceeded() {
+			stopped = true
+
+			return false
+		}
+
+		var skip = cfg.skip.eval != nil && cfg.skip.Match(n)
+
+		if tx != nil {
+			tx.enter(n)
+		}
+
+		d.match(n, processMatch, func(matchErr *MatchError) bool {
+			switch {
+			case errors.Is(matchErr.Err, Stop):
+				stopped = true
+
+				return false
+			case errors.Is(matchErr.Err, SkipChildren):
+				skip = true
+
+				return true
+			}
 
-		return true
+			if cfg.fileSet != nil {
+				matchErr.Position = cfg.fileSet.Position(matchErr.Node.Pos())
+			}
+
+			errs = append(errs, matchErr)
+
+			if cfg.stopOnError {
+				stopped = true
+			}
+
+			return !stopped
+		})
+
+		var descend = !stopped && !skip
+		if tx != nil && !descend {
+			tx.leave()
+		}
+
+		return descend
 	})
+
+	if tx != nil {
+		for _, matchErr := range tx.verify() {
+			if cfg.fileSet != nil {
+				matchErr.Position = cfg.fileSet.Position(matchErr.Node.Pos())
+			}
+
+			errs = append(errs, matchErr)
+		}
+	}
+
+	switch {
+	case len(errs) == 0:
+		return limits.err
+	case limits.err != nil:
+		return errors.Join(errs, limits.err)
+	}
+
+	return errs
+}
+
+func intercept(interceptor Interceptor, process processFunc) processFunc {
+	return func(pm *Matcher, first ast.Node, s NodeSelections) error {
+		return interceptor(pm, first, s, func() error {
+			return process(pm, first, s)
+		})
+	}
 }